@@ -0,0 +1,126 @@
+// Command assets fingerprints the static assets for long-lived caching.
+//
+//	assets [-dir static]            write the manifest mapping each asset to its fingerprinted name
+//	assets -check [-dir static]     fail when the manifest is out of date
+//
+// Assets are the stylesheets, scripts, images and fonts under the css and js
+// directories. Both modes fail when a required asset, which the layout
+// loads on every page, is missing or empty. Each is served under a name carrying a hash of its content,
+// so a changed asset gets a new URL and browsers may cache every URL for
+// good. Run it after compiling Tailwind or vendoring scripts; "make assets"
+// does both.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFile names the manifest within the static root; the server reads
+// it as static.ManifestFile
+const manifestFile = "manifest.json"
+
+// assetDirs are the directories holding assets, relative to the static root
+var assetDirs = []string{"css", "js"}
+
+// assetExts are the extensions of files that are assets
+var assetExts = map[string]bool{
+	".css": true, ".js": true, ".map": true,
+	".svg": true, ".png": true, ".ico": true, ".woff2": true,
+}
+
+// requiredAssets are loaded by every page: the compiled Tailwind stylesheet
+// and the htmx build and extensions vendored at the versions pinned in the
+// Makefile. Keep it in step with static.Required; this command cannot import
+// that package, which needs a valid manifest to load.
+var requiredAssets = []string{
+	"css/main.css",
+	"js/htmx.min.js",
+	"js/ext/response-targets.js",
+}
+
+// hashLength is the number of hex digits of the content hash in a name
+const hashLength = 8
+
+func main() {
+	dir := flag.String("dir", "static", "static assets directory")
+	check := flag.Bool("check", false, "fail when the manifest is out of date instead of writing it")
+	flag.Parse()
+
+	fsys := os.DirFS(*dir)
+	if missing := missingAssets(fsys); len(missing) > 0 {
+		log.Fatalf("Required assets are missing or empty: %s; run make assets-css assets-vendor", strings.Join(missing, ", "))
+	}
+	manifest, err := fingerprint(fsys)
+	if err != nil {
+		log.Fatalf("Failed to fingerprint assets: %v", err)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode manifest: %v", err)
+	}
+	data = append(data, '\n')
+
+	manifestPath := filepath.Join(*dir, manifestFile)
+	if *check {
+		current, err := os.ReadFile(manifestPath)
+		if err != nil || !bytes.Equal(current, data) {
+			log.Fatalf("%s is out of date; run make assets", manifestPath)
+		}
+		return
+	}
+	if err := os.WriteFile(manifestPath, data, 0o644); err != nil {
+		log.Fatalf("Failed to write manifest: %v", err)
+	}
+	fmt.Printf("Fingerprinted %d assets into %s\n", len(manifest), manifestPath)
+}
+
+// fingerprint maps the path of every asset to its name with a content hash,
+// such as css/main.css to css/main.1a2b3c4d.css
+func fingerprint(fsys fs.FS) (map[string]string, error) {
+	manifest := map[string]string{}
+	for _, dir := range assetDirs {
+		err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || strings.HasPrefix(d.Name(), ".") || !assetExts[path.Ext(p)] {
+				return nil
+			}
+			data, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256(data)
+			ext := path.Ext(p)
+			manifest[p] = strings.TrimSuffix(p, ext) + "." + hex.EncodeToString(sum[:])[:hashLength] + ext
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// missingAssets returns the required assets that are missing or empty
+func missingAssets(fsys fs.FS) []string {
+	var missing []string
+	for _, name := range requiredAssets {
+		info, err := fs.Stat(fsys, name)
+		if err != nil || info.Size() == 0 {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
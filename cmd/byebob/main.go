@@ -0,0 +1,277 @@
+// Command byebob runs administrative tasks against the ByeBob database.
+//
+//	byebob migrate status           show the applied version and pending migrations
+//	byebob migrate up [N]           apply the next N pending migrations, or all
+//	byebob migrate down [N]         roll back the last N migrations (default 1)
+//	byebob migrate goto V           migrate up or down to version V
+//	byebob migrate force V          mark version V as applied and clear the dirty flag
+//	byebob migrate create NAME      create the next numbered pair of migration files
+//	byebob migrate lint [-all]      check pending migrations for destructive or locking changes
+//	byebob db bootstrap             create or update the database roles and users
+//	byebob config print [-redacted] print the effective configuration as YAML
+//
+// Every migrate command accepts -path, before its argument, to override
+// MIGRATIONS_PATH. Database settings are read from the environment, like the
+// server; these commands connect with the migration credentials (DB_USER or
+// RAILWAY_DB_URL) rather than as the application user.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/gfurduy/byebob/config"
+	"github.com/gfurduy/byebob/internal/logging"
+	"github.com/gfurduy/byebob/internal/repository"
+	"gopkg.in/yaml.v3"
+)
+
+// Login users provisioned alongside the configurable application user
+const (
+	adminDBUser    = "byebob_admin"
+	readonlyDBUser = "byebob_readonly"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		usage()
+	}
+
+	// Printing the configuration accepts the server's flags
+	if os.Args[1] == "config" {
+		if os.Args[2] != "print" {
+			usage()
+		}
+		printConfig(os.Args[3:])
+		return
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fatal("Failed to load config", err)
+	}
+	if _, err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		fatal("Failed to set up logging", err)
+	}
+
+	switch os.Args[1] {
+	case "migrate":
+		runMigrate(cfg, os.Args[2], os.Args[3:])
+	case "db":
+		if os.Args[2] != "bootstrap" || len(os.Args) > 3 {
+			usage()
+		}
+		bootstrap(cfg)
+	default:
+		usage()
+	}
+}
+
+// runMigrate runs a migrate subcommand
+func runMigrate(cfg *config.Config, command string, args []string) {
+	cmd := flag.NewFlagSet("migrate "+command, flag.ExitOnError)
+	path := cmd.String("path", cfg.MigrationsPath, "migrations directory")
+	all := cmd.Bool("all", false, "lint every migration, not just pending ones")
+	if err := cmd.Parse(args); err != nil {
+		fatal("Invalid arguments", err)
+	}
+
+	// Creating files needs no database connection
+	if command == "create" {
+		if cmd.NArg() != 1 {
+			usage()
+		}
+		if err := repository.NewMigrationManager(nil, cfg).CreateMigration(cmd.Arg(0), *path); err != nil {
+			fatal("Failed to create migration", err)
+		}
+		return
+	}
+
+	// Linting every migration needs no database connection either
+	if command == "lint" && *all {
+		if !lint(repository.LintMigrations(*path, 0)) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	db, err := repository.NewAdminDBPool(cfg)
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+
+	migrations := repository.NewMigrationManager(db.GetPool(), cfg)
+	if command == "lint" {
+		ok := lint(migrations.LintPending(*path))
+		db.Close()
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	err = migrate(migrations, command, *path, cmd.Args())
+	db.Close()
+	if err != nil {
+		fatal("Migration failed", err)
+	}
+}
+
+// printConfig writes the configuration the server would load with the given
+// flags, as a config file
+func printConfig(args []string) {
+	cmd := flag.NewFlagSet("config print", flag.ExitOnError)
+	redacted := cmd.Bool("redacted", false, "replace secrets with REDACTED")
+	cfg, err := config.Load(cmd, args)
+	if err != nil {
+		fatal("Failed to load config", err)
+	}
+	if *redacted {
+		cfg = cfg.Redacted()
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		fatal("Failed to encode config", err)
+	}
+	os.Stdout.Write(out)
+}
+
+// bootstrap provisions the database roles and users from the configured
+// passwords
+func bootstrap(cfg *config.Config) {
+	db, err := repository.NewAdminDBPool(cfg)
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	defer db.Close()
+
+	users := []repository.DBUser{
+		{Name: cfg.AppDBUser, Password: cfg.AppDBPassword, Role: repository.AppRole},
+		{Name: adminDBUser, Password: cfg.AdminDBPassword, Role: repository.AdminRole},
+		{Name: readonlyDBUser, Password: cfg.ReadonlyDBPassword, Role: repository.ReadonlyRole},
+	}
+	if err := repository.BootstrapRoles(context.Background(), db.GetPool(), users); err != nil {
+		db.Close()
+		fatal("Failed to bootstrap database roles", err)
+	}
+	fmt.Println("Database roles are up to date")
+}
+
+// migrate runs a migrate subcommand that changes or reports the schema version
+func migrate(migrations *repository.MigrationManager, command, path string, args []string) error {
+	switch command {
+	case "status":
+		status, err := migrations.Status(path)
+		if err != nil {
+			return err
+		}
+		printStatus(status)
+		return nil
+	case "up":
+		n, err := optionalCount(args, 0)
+		if err != nil {
+			return err
+		}
+		return migrations.Up(path, n)
+	case "down":
+		n, err := optionalCount(args, 1)
+		if err != nil {
+			return err
+		}
+		return migrations.Down(path, n)
+	case "goto":
+		version, err := requiredVersion(args)
+		if err != nil {
+			return err
+		}
+		return migrations.Goto(path, uint(version))
+	case "force":
+		version, err := requiredVersion(args)
+		if err != nil {
+			return err
+		}
+		return migrations.Force(path, version)
+	default:
+		usage()
+		return nil
+	}
+}
+
+// lint prints the issues found in migrations and reports whether there were
+// none
+func lint(issues []repository.MigrationIssue, err error) bool {
+	if err != nil {
+		slog.Error("Failed to lint migrations", "error", err)
+		return false
+	}
+	if len(issues) == 0 {
+		fmt.Println("No risky operations found")
+		return true
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf("\n%d issues found; fix them or add a \"-- lint:ignore <rule>\" comment above a statement that is safe\n", len(issues))
+	return false
+}
+
+// printStatus writes the migration status in a human readable form
+func printStatus(status *repository.MigrationStatus) {
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty: fix the database, then run force)"
+	}
+	fmt.Printf("Current version: %d%s\n", status.Version, dirty)
+
+	if len(status.Pending) == 0 {
+		fmt.Println("No pending migrations")
+		return
+	}
+	fmt.Printf("%d pending migrations:\n", len(status.Pending))
+	for _, migration := range status.Pending {
+		fmt.Printf("  %03d %s\n", migration.Version, migration.Name)
+	}
+}
+
+// optionalCount parses an optional positive step count
+func optionalCount(args []string, defaultValue int) (int, error) {
+	if len(args) == 0 {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 || len(args) > 1 {
+		return 0, fmt.Errorf("expected a positive number of migrations, got %q", args)
+	}
+	return n, nil
+}
+
+// requiredVersion parses the version argument of goto and force
+func requiredVersion(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("expected a single migration version")
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid migration version %q", args[0])
+	}
+	return version, nil
+}
+
+// usage prints the available commands and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: byebob migrate status|up|down|goto|force|create|lint [-path dir] [-all] [N|V|NAME]")
+	fmt.Fprintln(os.Stderr, "       byebob db bootstrap")
+	fmt.Fprintln(os.Stderr, "       byebob config print [-redacted] [-config file] [flags]")
+	os.Exit(2)
+}
+
+// fatal logs an error and exits with a failure status
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,114 @@
+// Command keys manages the master key file used for field encryption.
+//
+//	keys init -file keys.json       create a key file with a fresh master key
+//	keys rotate -file keys.json     add a new master key and make it active
+//	keys reencrypt                  rewrite encrypted columns under the active key
+//
+// reencrypt reads the database settings and ENCRYPTION_KEY_FILE from the
+// environment, like the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gfurduy/byebob/config"
+	"github.com/gfurduy/byebob/internal/encryption"
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cmd := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	path := cmd.String("file", "", "path to the key file")
+	if err := cmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+
+	switch os.Args[1] {
+	case "init":
+		requireFile(*path)
+		kf, err := encryption.CreateKeyFile(*path)
+		if err != nil {
+			log.Fatalf("Failed to create key file: %v", err)
+		}
+		fmt.Printf("Created %s with active key %s\n", *path, kf.ActiveKeyID())
+	case "rotate":
+		requireFile(*path)
+		kf, err := encryption.LoadKeyFile(*path)
+		if err != nil {
+			log.Fatalf("Failed to load key file: %v", err)
+		}
+		id, err := kf.Rotate()
+		if err != nil {
+			log.Fatalf("Failed to rotate key: %v", err)
+		}
+		if err := kf.Save(*path); err != nil {
+			log.Fatalf("Failed to save key file: %v", err)
+		}
+		fmt.Printf("Active key is now %s; run reencrypt before removing older keys\n", id)
+	case "reencrypt":
+		reencrypt()
+	default:
+		usage()
+	}
+}
+
+// reencrypt rewrites every encrypted column under the active master key
+func reencrypt() {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.EncryptionKeyFile == "" {
+		log.Fatal("ENCRYPTION_KEY_FILE is not set")
+	}
+	kf, err := encryption.LoadKeyFile(cfg.EncryptionKeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load key file: %v", err)
+	}
+
+	db, err := repository.InitGlobalDBPool(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer repository.CloseGlobalDBPool()
+
+	repos := repository.NewPostgresFactory(db.GetPool())
+	repos.SetFieldCipher(encryption.NewEncryptor(kf))
+
+	ctx := context.Background()
+	tenants, err := repos.Tenants().List(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list tenants: %v", err)
+	}
+
+	for _, tenant := range tenants {
+		tenantCtx := repository.ContextWithActor(repository.ContextWithTenant(ctx, tenant.ID), repository.SystemActor)
+		count, err := repos.Employees().Reencrypt(tenantCtx)
+		if err != nil {
+			log.Fatalf("Reencrypted %d employees of %s before failing: %v", count, tenant.Slug, err)
+		}
+		fmt.Printf("Reencrypted %d employees of %s with key %s\n", count, tenant.Slug, kf.ActiveKeyID())
+	}
+}
+
+// requireFile exits if no key file path was given
+func requireFile(path string) {
+	if path == "" {
+		log.Fatal("-file is required")
+	}
+}
+
+// usage prints the available commands and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: keys init|rotate -file <path>")
+	fmt.Fprintln(os.Stderr, "       keys reencrypt")
+	os.Exit(2)
+}
@@ -0,0 +1,133 @@
+// Command privacy answers data subject access and erasure requests.
+//
+//	privacy export -employee <id> -out export.zip     write everything held about an employee to a ZIP archive
+//	privacy anonymise -employee <id>                   erase an employee's personal data
+//
+// Both act for HR in the tenant given by -tenant, the default tenant unless
+// set, and read the database, encryption and blob store settings from the
+// environment, like the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gfurduy/byebob/config"
+	"github.com/gfurduy/byebob/internal/encryption"
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gfurduy/byebob/internal/storage"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cmd := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	employeeID := cmd.String("employee", "", "ID of the employee")
+	tenantSlug := cmd.String("tenant", "", "slug of the employee's tenant (default: the default tenant)")
+	out := cmd.String("out", "", "path of the ZIP archive to write")
+	if err := cmd.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+	if *employeeID == "" {
+		log.Fatal("-employee is required")
+	}
+
+	switch os.Args[1] {
+	case "export":
+		if *out == "" {
+			log.Fatal("-out is required")
+		}
+		svc, ctx, closeDB := connect(*tenantSlug)
+		defer closeDB()
+		export(ctx, svc, *employeeID, *out)
+	case "anonymise":
+		svc, ctx, closeDB := connect(*tenantSlug)
+		defer closeDB()
+		if err := svc.Privacy.Anonymise(ctx, *employeeID); err != nil {
+			log.Fatalf("Failed to anonymise employee: %v", err)
+		}
+		fmt.Printf("Anonymised employee %s\n", *employeeID)
+	default:
+		usage()
+	}
+}
+
+// export writes everything held about an employee to a ZIP archive
+func export(ctx context.Context, svc *services.Services, employeeID, path string) {
+	data, err := svc.Privacy.Export(ctx, employeeID)
+	if err != nil {
+		log.Fatalf("Failed to export employee: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatalf("Failed to create export: %v", err)
+	}
+	if err := data.WriteZip(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		log.Fatalf("Failed to write export: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("Failed to write export: %v", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
+
+// connect sets up the services like the server does and returns a context
+// acting for HR in the tenant. The returned function closes the database
+// pool.
+func connect(tenantSlug string) (*services.Services, context.Context, func()) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if tenantSlug == "" {
+		tenantSlug = cfg.DefaultTenant
+	}
+
+	db, err := repository.InitGlobalDBPool(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	repos := repository.NewPostgresFactory(db.GetPool())
+	if cfg.EncryptionKeyFile != "" {
+		kf, err := encryption.LoadKeyFile(cfg.EncryptionKeyFile)
+		if err != nil {
+			repository.CloseGlobalDBPool()
+			log.Fatalf("Failed to load key file: %v", err)
+		}
+		repos.SetFieldCipher(encryption.NewEncryptor(kf))
+	}
+	svc := services.New(repos)
+
+	blobs, err := storage.Open(cfg)
+	if err != nil {
+		repository.CloseGlobalDBPool()
+		log.Fatalf("Failed to set up blob storage: %v", err)
+	}
+	svc.Profiles.SetPictureStore(blobs, int64(cfg.MaxUploadBytes))
+
+	ctx := context.Background()
+	tenant, err := repos.Tenants().GetBySlug(ctx, tenantSlug)
+	if err != nil {
+		repository.CloseGlobalDBPool()
+		log.Fatalf("Failed to find tenant %s: %v", tenantSlug, err)
+	}
+	ctx = repository.ContextWithActor(repository.ContextWithTenant(ctx, tenant.ID), repository.SystemActor)
+	return svc, ctx, repository.CloseGlobalDBPool
+}
+
+// usage prints the available commands and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: privacy export -employee <id> -out <path> [-tenant <slug>]")
+	fmt.Fprintln(os.Stderr, "       privacy anonymise -employee <id> [-tenant <slug>]")
+	os.Exit(2)
+}
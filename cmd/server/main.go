@@ -1,19 +1,35 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gfurduy/byebob/config"
+	"github.com/gfurduy/byebob/internal/encryption"
 	"github.com/gfurduy/byebob/internal/handlers"
+	"github.com/gfurduy/byebob/internal/health"
+	"github.com/gfurduy/byebob/internal/logging"
+	"github.com/gfurduy/byebob/internal/metrics"
+	"github.com/gfurduy/byebob/internal/middleware"
 	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gfurduy/byebob/internal/storage"
+	"github.com/gfurduy/byebob/internal/tracing"
+	"github.com/gfurduy/byebob/static"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 // Version and BuildTime are set during build
@@ -23,83 +39,371 @@ var (
 )
 
 func main() {
-	// Print version info
-	fmt.Printf("ByeBob %s (built at %s)\n", Version, BuildTime)
+	os.Exit(run())
+}
+
+// Exit codes reported to the process supervisor
+const (
+	exitOK      = 0
+	exitFailure = 1
+)
 
-	// Load configuration
-	cfg, err := config.NewConfig()
+// run starts the server and blocks until it has shut down, returning the
+// exit code. Returning rather than exiting lets deferred cleanup run.
+func run() int {
+	// Load configuration, with command-line flags overriding the environment
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return fail("Failed to load config", err)
+	}
+
+	// Log structured records from here on
+	if _, err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		return fail("Failed to set up logging", err)
+	}
+	slog.Info("Starting ByeBob", "version", Version, "build_time", BuildTime)
+
+	// Pages are unusable without the stylesheet and htmx; only development
+	// runs may start without them
+	if missing := static.Missing(static.FS); len(missing) > 0 {
+		err := fmt.Errorf("%s missing or empty; run make assets-css assets-vendor", strings.Join(missing, ", "))
+		if !cfg.IsDevelopment() {
+			return fail("Required static assets are not built", err)
+		}
+		slog.Warn("Required static assets are not built; pages will load without styles or htmx", "error", err)
 	}
 	
-	// Initialize global database connection pool
+	// Export traces when an exporter is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, Version)
+	if err != nil {
+		return fail("Failed to set up tracing", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	// Apply pending migrations when enabled, with the migration credentials;
+	// replicas take turns via an advisory lock
+	if cfg.MigrateOnStartup {
+		if err := migrate(cfg); err != nil {
+			return fail("Failed to run migrations", err)
+		}
+	}
+
+	// Initialize global database connection pool as the application user
 	db, err := repository.InitGlobalDBPool(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return fail("Failed to connect to database", err)
 	}
 	defer repository.CloseGlobalDBPool()
+	if cfg.AppDBPassword == "" {
+		slog.Warn("DB_APP_PASSWORD is not set; connecting with the migration credentials instead of the application user")
+	}
 	
-	// Initialize repository
-	repo := repository.NewPostgresRepository(db.GetPool())
+	// Initialize repositories and services
+	repos := repository.NewPostgresFactory(db.GetPool())
+	if db.Replica != nil {
+		repos.SetReadReplica(db.Replica)
+	}
+	if cfg.EncryptionKeyFile != "" {
+		keys, err := encryption.LoadKeyFile(cfg.EncryptionKeyFile)
+		if err != nil {
+			return fail("Failed to load encryption keys", err)
+		}
+		repos.SetFieldCipher(encryption.NewEncryptor(keys))
+	} else {
+		slog.Warn("ENCRYPTION_KEY_FILE is not set; sensitive fields are stored unencrypted")
+	}
+	svc := services.New(repos)
+	svc.Tenants.SetDefaultTenant(cfg.DefaultTenant)
+
+	// Uploaded profile pictures are kept on disk or in an S3-compatible bucket
+	blobs, err := storage.Open(cfg)
+	if err != nil {
+		return fail("Failed to set up blob storage", err)
+	}
+	svc.Profiles.SetPictureStore(blobs, int64(cfg.MaxUploadBytes))
+
+	// Background workers run until the context is cancelled during shutdown.
+	// The deferred wait runs before the pool is closed.
+	ctx, cancel := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer workers.Wait()
+	defer cancel()
+
+	// Purge soft-deleted records once their retention period has passed
+	retention := services.NewRetentionService(repos, time.Duration(cfg.RetentionDays)*24*time.Hour)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		retention.Run(ctx, purgeInterval)
+	}()
+
+	// Route reads to the replica only while it keeps up with the primary
+	if db.Replica != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			db.Replica.Monitor(ctx, replicaCheckInterval)
+		}()
+	}
+
+	checker, err := readinessChecks(cfg, db, retention, blobs)
+	if err != nil {
+		return fail("Failed to set up readiness checks", err)
+	}
+
+	auth, err := middleware.NewAuthenticator(cfg.ClerkPubKey, cfg.IdentityProxies)
+	if err != nil {
+		return fail("Failed to set up authentication", err)
+	}
+	auth.TrustTenantHeader(cfg.IsDevelopment())
+
+	// Rate limit counters and CSRF tokens live in memory unless they are
+	// shared through the database
+	var store fiber.Storage
+	if cfg.HTTPStore == "postgres" {
+		store = repository.NewPostgresStorage(db.GetPool(), storageGCInterval)
+		defer store.Close()
+	}
 	
 	// Create a new Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "ByeBob App",
 		ServerHeader: "Fiber",
 		ErrorHandler: customErrorHandler,
+
+		// Idle keep-alive connections would otherwise hold up the drain
+		IdleTimeout: idleTimeout,
+
+		// Larger bodies are rejected with 413 before they are read. Only
+		// uploads may use more than MaxBodyBytes; see the BodyLimit middleware.
+		BodyLimit: max(cfg.MaxBodyBytes, cfg.MaxUploadBytes+multipartOverhead),
+
+		// Client IPs for rate limiting, from the load balancer's header
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableIPValidation:      true,
+		EnableTrustedProxyCheck: len(cfg.TrustedProxies) > 0,
+		TrustedProxies:          cfg.TrustedProxies,
+
+		// The startup banner would break up structured logs
+		DisableStartupMessage: true,
 	})
 
-	// Use global middlewares
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
+	// Use global middlewares. Panics are recovered innermost so that the
+	// logger, metrics and tracing record the resulting 500.
+	app.Use(middleware.RequestID())
+	app.Use(middleware.RequestLogger())
+	app.Use(metrics.Middleware())
+	app.Use(middleware.Tracing())
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace:  true,
+		StackTraceHandler: logPanic,
 	}))
-	app.Use(recover.New())
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: cfg.AllowedOrigins[0],
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
+		AllowOrigins: strings.Join(cfg.AllowedOrigins, ","),
+		AllowMethods: "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 	}))
+	app.Use(middleware.SecurityHeaders())
+	app.Use(middleware.BodyLimit(cfg.MaxBodyBytes, handlers.IsUpload))
+
+	// Static files, embedded so the binary runs from any directory and
+	// served under fingerprinted names
+	app.Get("/static/*", handlers.StaticAssets())
 
-	// Static files
-	app.Static("/static", "./static")
+	// Uploaded blobs, such as profile pictures, under content-addressed keys
+	app.Get(services.MediaPath+"*", handlers.Media(blobs))
+
+	// Liveness and readiness probes. /api/v1/health is kept for existing
+	// monitors and reports readiness.
+	build := handlers.BuildInfo{Version: Version, BuildTime: BuildTime}
+	readyz := handlers.Readyz(checker, build)
+	app.Get("/livez", handlers.Livez(build))
+	app.Get("/readyz", readyz)
+	app.Get("/api/v1/health", readyz)
+
+	// Prometheus metrics, and database pool and query statistics as JSON
+	metrics.RegisterDB(db)
+	metrics.RegisterBusiness(repos)
+	app.Get("/metrics", metrics.Handler())
+	app.Get("/metrics/db", handlers.DBStats(db))
+
+	// Identify the acting user from a verified Clerk session token, or the
+	// identity headers of a trusted proxy
+	app.Use(auth.Actor())
+
+	// Application routes are rate limited per client IP and acting user, and
+	// protected against cross-site request forgery
+	app.Use(middleware.RateLimitByIP(store, cfg.RateLimitPerIP))
+	app.Use(middleware.RateLimitByUser(store, cfg.RateLimitPerUser))
+	app.Use(middleware.CSRF(store, !cfg.IsDevelopment()))
+
+	// Restarting the query statistics changes state, so only HR may do it
+	app.Post("/metrics/db/reset", middleware.RequireHR(), handlers.ResetDBStats(db))
 
 	// Setup routes
-	handlers.SetupRoutes(app, repo)
+	handlers.SetupRoutes(app, svc)
+	app.Use(metrics.NotFound())
 
-	// Start the server in a goroutine
+	// Serve until the listener fails or a shutdown signal arrives
+	listenErr := make(chan error, 1)
 	go func() {
-		if err := app.Listen(":" + cfg.Port); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
-		}
+		listenErr <- app.Listen(":" + cfg.Port)
 	}()
+	slog.Info("Server started", "port", cfg.Port, "environment", cfg.Environment)
 
-	fmt.Printf("Server started on http://localhost:%s in %s mode\n", 
-		cfg.Port, cfg.Environment)
-
-	// Wait for interrupt signal to gracefully shut down the server
-	quit := make(chan os.Signal, 1)
+	quit := make(chan os.Signal, 2)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
+	select {
+	case err := <-listenErr:
+		return fail("Failed to start server", err)
+	case sig := <-quit:
+		slog.Info("Shutting down server", "signal", sig.String())
+	}
+
+	// A second signal skips the drain
+	go func() {
+		<-quit
+		slog.Error("Received second signal, exiting without draining")
+		os.Exit(exitFailure)
+	}()
 
-	fmt.Println("Shutting down server...")
-	if err := app.Shutdown(); err != nil {
-		log.Fatalf("Error shutting down server: %v", err)
+	return shutdown(app, checker, cfg)
+}
+
+// shutdown fails readiness so load balancers stop routing new requests here,
+// then stops accepting connections and waits for in-flight requests. Workers,
+// the pool and the tracer are stopped by run's deferred calls afterwards.
+func shutdown(app *fiber.App, checker *health.Checker, cfg *config.Config) int {
+	checker.Drain()
+	if delay := time.Duration(cfg.ShutdownDelaySeconds) * time.Second; delay > 0 {
+		slog.Info("Readiness failing, waiting before closing the listener", "delay", delay.String())
+		time.Sleep(delay)
+	}
+
+	timeout := time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+	if err := app.ShutdownWithTimeout(timeout); err != nil {
+		slog.Error("In-flight requests did not complete in time", "timeout", timeout.String(), "error", err)
+		return exitFailure
+	}
+	slog.Info("Server gracefully stopped")
+	return exitOK
+}
+
+// migrate applies pending migrations over a short-lived connection with the
+// migration credentials
+func migrate(cfg *config.Config) error {
+	db, err := repository.NewAdminDBPool(cfg)
+	if err != nil {
+		return err
 	}
-	fmt.Println("Server gracefully stopped")
+	defer db.Close()
+
+	migrations := repository.NewMigrationManager(db.GetPool(), cfg)
+	return migrations.RunMigrationsLocked(context.Background(), cfg.MigrationsPath)
+}
+
+// readinessChecks collects the checks deciding whether the server is ready:
+// the database answers, its schema is at least at the newest migration this
+// build ships, the blob store is reachable and the retention worker keeps
+// running
+func readinessChecks(cfg *config.Config, db *repository.DBPool, retention *services.RetentionService, blobs storage.BlobStore) (*health.Checker, error) {
+	expected, err := repository.LatestMigration(cfg.MigrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	migrations := repository.NewMigrationManager(db.GetPool(), cfg)
+
+	checker := health.NewChecker()
+	checker.Add("database", db.HealthCheck)
+	checker.Add("migrations", func(context.Context) error {
+		return migrations.CheckVersion(cfg.MigrationsPath, expected)
+	})
+	checker.Add("blob_store", blobs.Check)
+	checker.Add("retention_worker", retention.Heartbeat().Check)
+	return checker, nil
+}
+
+// fail logs an error and returns the failure exit code
+func fail(msg string, err error) int {
+	slog.Error(msg, "error", err)
+	return exitFailure
+}
+
+// logPanic logs a recovered panic with its stack trace
+func logPanic(c *fiber.Ctx, e interface{}) {
+	slog.ErrorContext(c.UserContext(), "Recovered from panic",
+		"method", c.Method(), "path", c.Path(), "panic", fmt.Sprint(e), "stack", string(debug.Stack()))
+}
+
+// purgeInterval is how often expired soft-deleted records are purged
+const purgeInterval = 24 * time.Hour
+
+// replicaCheckInterval is how often the read replica's lag is measured
+const replicaCheckInterval = 5 * time.Second
+
+// storageGCInterval is how often expired rate limit counters and CSRF tokens
+// are deleted from the database
+const storageGCInterval = 10 * time.Minute
+
+// multipartOverhead is the room left in an upload's body for the multipart
+// framing and form fields around the file
+const multipartOverhead = 64 << 10
+
+// idleTimeout is how long an idle keep-alive connection is kept open
+const idleTimeout = 60 * time.Second
+
+// mimeProblemJSON is the content type of error responses
+const mimeProblemJSON = "application/problem+json"
+
+// problemDetails is an RFC 7807 error response body
+type problemDetails struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Errors   []services.FieldError `json:"errors,omitempty"`
 }
 
 // customErrorHandler handles errors thrown by Fiber handlers
 func customErrorHandler(c *fiber.Ctx, err error) error {
 	// Default status code is 500
 	statusCode := fiber.StatusInternalServerError
+	detail := err.Error()
 
-	// Check if it's a Fiber error
-	if e, ok := err.(*fiber.Error); ok {
-		statusCode = e.Code
+	// Map domain and Fiber errors to status codes
+	var fiberErr *fiber.Error
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		statusCode = fiber.StatusUnprocessableEntity
+		detail = "One or more fields are invalid"
+	case errors.Is(err, services.ErrNotFound):
+		statusCode = fiber.StatusNotFound
+	case errors.Is(err, services.ErrConflict):
+		statusCode = fiber.StatusConflict
+	case errors.Is(err, services.ErrForbidden):
+		statusCode = fiber.StatusForbidden
+	case errors.As(err, &fiberErr):
+		statusCode = fiberErr.Code
+		detail = fiberErr.Message
 	}
 
-	// Return JSON error response
-	return c.Status(statusCode).JSON(fiber.Map{
-		"error": true,
-		"msg":   err.Error(),
-	})
+	// Don't leak internal error details to clients
+	if statusCode == fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "Unhandled error", "method", c.Method(), "path", c.Path(), "error", err)
+		detail = "An unexpected error occurred"
+	}
+
+	// Return problem+json error response
+	return c.Status(statusCode).JSON(problemDetails{
+		Type:     "about:blank",
+		Title:    utils.StatusMessage(statusCode),
+		Status:   statusCode,
+		Detail:   detail,
+		Instance: c.OriginalURL(),
+		Errors:   services.FieldErrorsOf(err),
+	}, mimeProblemJSON)
 }
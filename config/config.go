@@ -2,82 +2,117 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
-
-	"github.com/joho/godotenv"
 )
 
-// Config holds all configuration for the application
+// Config holds all configuration for the application. Each field is named
+// by its key in a config file, from which its environment variable and flag
+// names derive unless given; see Load. Secret fields may instead be read
+// from the file named by their variable with a _FILE suffix, and are hidden
+// by Redacted.
 type Config struct {
 	// Server config
-	Port          string
-	Environment   string
-	AllowedOrigins []string
+	Port           string   `key:"port" default:"3000"`
+	Environment    string   `key:"environment" env:"GO_ENV" default:"development"`
+	AllowedOrigins []string `key:"allowed_origins" default:"*"`
+
+	// Request limits. Rate limits are per minute, 0 disabling them; counters
+	// and CSRF tokens are kept in memory or, shared by every instance, in
+	// postgres. ProxyHeader names the header carrying the client IP behind
+	// a load balancer, trusted only from TrustedProxies when any are listed.
+	// Profile picture uploads may be up to MaxUploadBytes instead of
+	// MaxBodyBytes.
+	MaxBodyBytes     int      `key:"max_body_bytes" default:"1048576"`
+	MaxUploadBytes   int      `key:"max_upload_bytes" default:"5242880"`
+	RateLimitPerIP   int      `key:"rate_limit_per_ip" default:"600"`
+	RateLimitPerUser int      `key:"rate_limit_per_user" default:"300"`
+	HTTPStore        string   `key:"http_store" default:"memory"`
+	ProxyHeader      string   `key:"proxy_header"`
+	TrustedProxies   []string `key:"trusted_proxies"`
+
+	// Shutdown config; on SIGTERM readiness fails for ShutdownDelaySeconds
+	// before the server stops accepting connections, then in-flight requests
+	// get ShutdownTimeoutSeconds to complete
+	ShutdownDelaySeconds   int `key:"shutdown_delay" default:"5"`
+	ShutdownTimeoutSeconds int `key:"shutdown_timeout" default:"30"`
 
 	// Database config
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBSSLMode  string
-	
-	// Railway config
-	RailwayDBURL string
+	DBHost     string `key:"db_host" default:"localhost"`
+	DBPort     string `key:"db_port" default:"5432"`
+	DBUser     string `key:"db_user" default:"postgres"`
+	DBPassword string `key:"db_password" default:"postgres" secret:"true"`
+	DBName     string `key:"db_name" default:"byebob"`
+	DBSSLMode  string `key:"db_sslmode" default:"disable"`
 
-	// Clerk auth config
-	ClerkSecretKey string
-	ClerkPubKey    string
+	// Railway config
+	RailwayDBURL string `key:"railway_db_url" secret:"true"`
+
+	// Read replica config; reads fall back to the primary when the replica
+	// lags more than ReplicaMaxLagSeconds
+	ReplicaDBURL         string `key:"db_replica_url" secret:"true"`
+	ReplicaMaxLagSeconds int    `key:"db_replica_max_lag" default:"10"`
+
+	// Queries slower than this are logged; 0 disables slow-query logging
+	SlowQueryThresholdMs int `key:"slow_query_threshold_ms" default:"200"`
+
+	// Database users provisioned by "byebob db bootstrap". The server connects
+	// as the application user; DB_USER is only used for migrations and
+	// bootstrapping.
+	AppDBUser          string `key:"db_app_user" default:"byebob_app"`
+	AppDBPassword      string `key:"db_app_password" secret:"true"`
+	AdminDBPassword    string `key:"db_admin_password" secret:"true"`
+	ReadonlyDBPassword string `key:"db_readonly_password" secret:"true"`
+
+	// Auth config. Bearer tokens are Clerk session tokens, verified with
+	// ClerkPubKey, the PEM public key Clerk signs them with. The X-User-ID and
+	// X-User-Roles headers are trusted only on requests coming straight from
+	// IdentityProxies, IPs or CIDR ranges of an authenticating proxy.
+	ClerkSecretKey  string   `key:"clerk_secret_key" secret:"true"`
+	ClerkPubKey     string   `key:"clerk_pub_key" secret:"true"`
+	IdentityProxies []string `key:"identity_proxies"`
+
+	// Data retention config
+	RetentionDays int `key:"retention_days" default:"90"`
+
+	// Field encryption config
+	EncryptionKeyFile string `key:"encryption_key_file"`
+
+	// Blob storage config for uploaded profile pictures; the store is "local",
+	// keeping blobs under BlobDir, or "s3", keeping them in an S3-compatible
+	// bucket such as one on MinIO
+	BlobStore   string `key:"blob_store" default:"local"`
+	BlobDir     string `key:"blob_dir" default:"data/blobs"`
+	S3Endpoint  string `key:"s3_endpoint"`
+	S3Bucket    string `key:"s3_bucket"`
+	S3Region    string `key:"s3_region" default:"us-east-1"`
+	S3AccessKey string `key:"s3_access_key" secret:"true"`
+	S3SecretKey string `key:"s3_secret_key" secret:"true"`
+
+	// Tenancy config
+	DefaultTenant string `key:"default_tenant" default:"default"`
+
+	// Migration config; an empty path uses the migrations embedded in the binary
+	MigrationsPath   string `key:"migrations_path"`
+	MigrateOnStartup bool   `key:"migrate_on_startup"`
+
+	// Logging config; the level is debug, info, warn or error and the format
+	// json or text
+	LogLevel  string `key:"log_level" default:"info"`
+	LogFormat string `key:"log_format" default:"json"`
+
+	// Tracing config; the exporter is "otlp", "stdout" or empty to disable
+	// tracing. The OTLP exporter reads its endpoint from the standard
+	// OTEL_EXPORTER_OTLP_* variables.
+	TracingExporter string `key:"tracing_exporter"`
+	ServiceName     string `key:"service_name" env:"OTEL_SERVICE_NAME" default:"byebob"`
 }
 
-// NewConfig loads configuration from environment variables
+// NewConfig loads configuration from the defaults, the config file named by
+// CONFIG_FILE and environment variables, without command-line flags
 func NewConfig() (*Config, error) {
-	// Determine environment
-	env := os.Getenv("GO_ENV")
-	if env == "" {
-		env = "development"
-	}
-
-	// Load env file based on environment
-	envFile := ".env"
-	if env != "development" {
-		envFile = fmt.Sprintf(".env.%s", env)
-	}
-
-	// Load .env file if it exists
-	if _, err := os.Stat(envFile); err == nil {
-		if err := godotenv.Load(envFile); err != nil {
-			return nil, fmt.Errorf("error loading %s file: %w", envFile, err)
-		}
-	}
-
-	// Create config
-	cfg := &Config{
-		// Server config
-		Port:          getEnv("PORT", "3000"),
-		Environment:   env,
-		AllowedOrigins: strings.Split(getEnv("ALLOWED_ORIGINS", "*"), ","),
-
-		// Database config
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "postgres"),
-		DBName:     getEnv("DB_NAME", "byebob"),
-		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
-		
-		// Railway config
-		RailwayDBURL: getEnv("RAILWAY_DB_URL", ""),
-
-		// Clerk auth config
-		ClerkSecretKey: getEnv("CLERK_SECRET_KEY", ""),
-		ClerkPubKey:    getEnv("CLERK_PUB_KEY", ""),
-	}
-
-	return cfg, nil
+	return Load(nil, nil)
 }
 
 // PostgresConnectionString returns the PostgreSQL connection string
@@ -92,29 +127,36 @@ func (c *Config) PostgresConnectionString() string {
 		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName, c.DBSSLMode)
 }
 
-// Helper functions for retrieving environment variables
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// AppConnectionString returns the connection string of the least-privileged
+// application user. It falls back to the migration connection when no
+// application password is configured.
+func (c *Config) AppConnectionString() string {
+	if c.AppDBPassword == "" {
+		return c.PostgresConnectionString()
 	}
-	return value
-}
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := getEnv(key, "")
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+	u, err := url.Parse(c.PostgresConnectionString())
+	if err != nil {
+		return c.PostgresConnectionString()
 	}
-	return defaultValue
+	u.User = url.UserPassword(c.AppDBUser, c.AppDBPassword)
+	return u.String()
 }
 
-func getEnvAsBool(key string, defaultValue bool) bool {
-	valueStr := getEnv(key, "")
-	if value, err := strconv.ParseBool(valueStr); err == nil {
-		return value
+// AppReplicaConnectionString returns the connection string of the read
+// replica with the application user's credentials, or an empty string when
+// no replica is configured
+func (c *Config) AppReplicaConnectionString() string {
+	if c.ReplicaDBURL == "" || c.AppDBPassword == "" {
+		return c.ReplicaDBURL
+	}
+
+	u, err := url.Parse(c.ReplicaDBURL)
+	if err != nil {
+		return c.ReplicaDBURL
 	}
-	return defaultValue
+	u.User = url.UserPassword(c.AppDBUser, c.AppDBPassword)
+	return u.String()
 }
 
 // GetProjectRoot returns the absolute path to the project root
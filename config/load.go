@@ -0,0 +1,287 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in Redacted
+const redacted = "REDACTED"
+
+// field describes a configurable Config field
+type field struct {
+	index  int
+	key    string
+	env    string
+	flag   string
+	def    string
+	secret bool
+}
+
+// fields lists the configurable fields of Config in declaration order
+var fields = describeFields()
+
+// describeFields reads the struct tags of Config
+func describeFields() []field {
+	t := reflect.TypeOf(Config{})
+	described := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		key := tag.Get("key")
+		if key == "" {
+			continue
+		}
+		f := field{
+			index:  i,
+			key:    key,
+			env:    tag.Get("env"),
+			flag:   strings.ReplaceAll(key, "_", "-"),
+			def:    tag.Get("default"),
+			secret: tag.Get("secret") == "true",
+		}
+		if f.env == "" {
+			f.env = strings.ToUpper(key)
+		}
+		described = append(described, f)
+	}
+	return described
+}
+
+// Load builds the configuration from layered sources, each overriding the
+// one before:
+//
+//  1. the defaults in the Config struct tags
+//  2. a YAML or TOML file, named by the -config flag or CONFIG_FILE
+//  3. environment variables, after loading .env (or .env.$GO_ENV outside
+//     development) when present; for secrets, a variable with a _FILE
+//     suffix names a file holding the value
+//  4. command-line flags, such as -db-host for db_host
+//
+// Flags are registered on fs and parsed from args; a nil fs skips them. Every
+// malformed value and failed validation is reported, not just the first.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	flags := map[string]string{}
+	configFile := os.Getenv("CONFIG_FILE")
+	if fs != nil {
+		fs.StringVar(&configFile, "config", configFile, "YAML or TOML config `file`")
+		for _, f := range fields {
+			fs.Func(f.flag, fmt.Sprintf("sets %s (env %s)", f.key, f.env), func(value string) error {
+				flags[f.key] = value
+				return nil
+			})
+		}
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnvFile(); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	var errs []error
+	for _, f := range fields {
+		if err := f.set(cfg, f.def); err != nil {
+			errs = append(errs, fmt.Errorf("default %s: %w", f.key, err))
+		}
+	}
+
+	if configFile != "" {
+		values, err := readFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			value, ok := values[f.key]
+			if !ok {
+				continue
+			}
+			delete(values, f.key)
+			if err := f.setFromFile(cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", configFile, f.key, err))
+			}
+		}
+		for key := range values {
+			errs = append(errs, fmt.Errorf("%s: unknown key %q", configFile, key))
+		}
+	}
+
+	for _, f := range fields {
+		value, ok, err := f.lookupEnv()
+		if err != nil {
+			errs = append(errs, err)
+		} else if ok {
+			if err := f.set(cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if value, ok := flags[f.key]; ok {
+			if err := f.set(cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", f.flag, err))
+			}
+		}
+	}
+
+	errs = append(errs, cfg.validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// loadEnvFile adds the variables of the environment's .env file, when
+// present, to those not already set
+func loadEnvFile() error {
+	envFile := ".env"
+	if env := os.Getenv("GO_ENV"); env != "" && env != "development" {
+		envFile = fmt.Sprintf(".env.%s", env)
+	}
+	if _, err := os.Stat(envFile); err != nil {
+		return nil
+	}
+	if err := godotenv.Load(envFile); err != nil {
+		return fmt.Errorf("error loading %s file: %w", envFile, err)
+	}
+	return nil
+}
+
+// readFile decodes a YAML or TOML config file, chosen by its extension
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	values := map[string]any{}
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, expected .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// lookupEnv returns the field's environment variable, or for secrets the
+// contents of the file named by its _FILE variable
+func (f field) lookupEnv() (string, bool, error) {
+	if f.secret {
+		if path := os.Getenv(f.env + "_FILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", false, fmt.Errorf("error reading %s_FILE: %w", f.env, err)
+			}
+			return strings.TrimSpace(string(data)), true, nil
+		}
+	}
+	value := os.Getenv(f.env)
+	return value, value != "", nil
+}
+
+// set parses a value into the field. Lists are comma-separated.
+func (f field) set(cfg *Config, value string) error {
+	v := reflect.ValueOf(cfg).Elem().Field(f.index)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		if value == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		if value == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		panic("config: unsupported field kind " + v.Kind().String())
+	}
+	return nil
+}
+
+// setFromFile sets the field from a decoded config file value, which may be
+// a list for list fields
+func (f field) setFromFile(cfg *Config, value any) error {
+	switch value := value.(type) {
+	case []any:
+		if reflect.ValueOf(cfg).Elem().Field(f.index).Kind() != reflect.Slice {
+			return errors.New("expected a single value, not a list")
+		}
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return f.set(cfg, strings.Join(items, ","))
+	case map[string]any:
+		return errors.New("expected a value, not a table")
+	case nil:
+		return f.set(cfg, "")
+	default:
+		return f.set(cfg, fmt.Sprint(value))
+	}
+}
+
+// Redacted returns a copy of the configuration with secrets replaced, safe
+// to log or print
+func (c *Config) Redacted() *Config {
+	clone := *c
+	for _, f := range fields {
+		v := reflect.ValueOf(&clone).Elem().Field(f.index)
+		if f.secret && v.String() != "" {
+			v.SetString(redacted)
+		}
+	}
+	return &clone
+}
+
+// MarshalYAML writes the configuration under its config file keys, so the
+// output of "byebob config print" can be loaded as a config file
+func (c *Config) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields {
+		value := &yaml.Node{}
+		if err := value.Encode(reflect.ValueOf(c).Elem().Field(f.index).Interface()); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.key}, value)
+	}
+	return node, nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// Accepted values of enumerated settings
+var (
+	sslModes         = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logFormats       = []string{"json", "text"}
+	tracingExporters = []string{"", "otlp", "stdout"}
+	httpStores       = []string{"memory", "postgres"}
+	blobStores       = []string{"local", "s3"}
+)
+
+// validate reports every setting that is out of range or malformed
+func (c *Config) validate() []error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(validPort(c.Port), "port", "%q is not a port number", c.Port)
+	check(c.Environment != "", "environment", "must not be empty")
+	check(len(c.AllowedOrigins) > 0, "allowed_origins", "must list at least one origin")
+	for _, origin := range c.AllowedOrigins {
+		check(validOrigin(origin), "allowed_origins", "%q is not \"*\" or a scheme://host[:port] origin", origin)
+	}
+	check(c.MaxBodyBytes > 0, "max_body_bytes", "must be positive")
+	check(c.MaxUploadBytes > 0, "max_upload_bytes", "must be positive")
+	check(c.RateLimitPerIP >= 0, "rate_limit_per_ip", "must not be negative")
+	check(c.RateLimitPerUser >= 0, "rate_limit_per_user", "must not be negative")
+	check(slices.Contains(httpStores, c.HTTPStore), "http_store", "%q is not one of %v", c.HTTPStore, httpStores)
+	for _, proxy := range c.TrustedProxies {
+		check(validIPOrCIDR(proxy), "trusted_proxies", "%q is not an IP address or CIDR range", proxy)
+	}
+	for _, proxy := range c.IdentityProxies {
+		check(validIPOrCIDR(proxy), "identity_proxies", "%q is not an IP address or CIDR range", proxy)
+	}
+	check(c.ShutdownDelaySeconds >= 0, "shutdown_delay", "must not be negative")
+	check(c.ShutdownTimeoutSeconds > 0, "shutdown_timeout", "must be positive")
+
+	if c.RailwayDBURL != "" {
+		check(validDatabaseURL(c.RailwayDBURL), "railway_db_url", "is not a postgres:// URL")
+	} else {
+		check(c.DBHost != "", "db_host", "must not be empty")
+		check(validPort(c.DBPort), "db_port", "%q is not a port number", c.DBPort)
+		check(c.DBUser != "", "db_user", "must not be empty")
+		check(c.DBName != "", "db_name", "must not be empty")
+	}
+	check(slices.Contains(sslModes, c.DBSSLMode), "db_sslmode", "%q is not one of %v", c.DBSSLMode, sslModes)
+	if c.ReplicaDBURL != "" {
+		check(validDatabaseURL(c.ReplicaDBURL), "db_replica_url", "is not a postgres:// URL")
+	}
+	check(c.ReplicaMaxLagSeconds >= 0, "db_replica_max_lag", "must not be negative")
+	check(c.SlowQueryThresholdMs >= 0, "slow_query_threshold_ms", "must not be negative")
+	check(c.AppDBUser != "", "db_app_user", "must not be empty")
+
+	check(c.RetentionDays > 0, "retention_days", "must be positive")
+
+	check(slices.Contains(blobStores, c.BlobStore), "blob_store", "%q is not one of %v", c.BlobStore, blobStores)
+	switch c.BlobStore {
+	case "local":
+		check(c.BlobDir != "", "blob_dir", "must not be empty")
+	case "s3":
+		check(validHTTPURL(c.S3Endpoint), "s3_endpoint", "%q is not an http(s) URL", c.S3Endpoint)
+		check(c.S3Bucket != "", "s3_bucket", "must not be empty")
+		check(c.S3AccessKey != "", "s3_access_key", "must not be empty")
+		check(c.S3SecretKey != "", "s3_secret_key", "must not be empty")
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "log_level", "%q is not debug, info, warn or error", c.LogLevel)
+	check(slices.Contains(logFormats, c.LogFormat), "log_format", "%q is not one of %v", c.LogFormat, logFormats)
+	check(slices.Contains(tracingExporters, c.TracingExporter), "tracing_exporter", "%q is not \"otlp\", \"stdout\" or empty", c.TracingExporter)
+	check(c.ServiceName != "", "service_name", "must not be empty")
+	return errs
+}
+
+// validPort reports whether a string is a TCP port number
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// validIPOrCIDR reports whether a string is an IP address or a CIDR range
+func validIPOrCIDR(s string) bool {
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+// validOrigin reports whether a CORS origin is the wildcard or an origin
+// without path
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/")
+}
+
+// validHTTPURL reports whether a string is an http(s) URL with a host
+func validHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validDatabaseURL reports whether a string is a PostgreSQL connection URL
+func validDatabaseURL(dsn string) bool {
+	u, err := url.Parse(dsn)
+	return err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") && u.Host != ""
+}
@@ -3,10 +3,10 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/gfurduy/byebob/config"
 )
@@ -35,7 +35,7 @@ func New(cfg *config.Config) (*DB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	pool, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to database: %w", err)
 	}
@@ -46,7 +46,7 @@ func New(cfg *config.Config) (*DB, error) {
 		return nil, fmt.Errorf("unable to ping database: %w", err)
 	}
 
-	log.Println("Successfully connected to PostgreSQL database")
+	slog.Info("Successfully connected to PostgreSQL database")
 	
 	return &DB{
 		Pool: pool,
@@ -58,6 +58,6 @@ func New(cfg *config.Config) (*DB, error) {
 func (db *DB) Close() {
 	if db.Pool != nil {
 		db.Pool.Close()
-		log.Println("Database connection pool closed")
+		slog.Info("Database connection pool closed")
 	}
 } 
\ No newline at end of file
@@ -2,7 +2,7 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gfurduy/byebob/config"
@@ -22,9 +22,9 @@ func Initialize(cfg *config.Config) (*DB, error) {
 			break
 		}
 
-		log.Printf("Failed to connect to database (attempt %d/%d): %v", i+1, maxRetries, err)
+		slog.Warn("Failed to connect to database", "attempt", i+1, "max_attempts", maxRetries, "error", err)
 		if i < maxRetries-1 {
-			log.Printf("Retrying in %v...", retryDelay)
+			slog.Info("Retrying database connection", "retry_in", retryDelay)
 			time.Sleep(retryDelay)
 			// Increase delay for next retry
 			retryDelay = retryDelay * 2
@@ -46,7 +46,7 @@ func Initialize(cfg *config.Config) (*DB, error) {
 		return nil, err
 	}
 
-	log.Println("Database initialization completed successfully")
+	slog.Info("Database initialization completed successfully")
 	
 	return db, nil
 }
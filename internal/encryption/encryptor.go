@@ -0,0 +1,110 @@
+// Package encryption provides application-level envelope encryption for
+// sensitive columns. Every value is encrypted with its own data key, which is
+// in turn wrapped by a master key from a KeyProvider.
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envelopePrefix marks values encrypted by this package. Values without it
+// are treated as plaintext written before encryption was enabled.
+const envelopePrefix = "enc:v1:"
+
+// ErrUnknownKey is returned when a value was encrypted with a master key
+// that the provider does not have
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// ErrMalformed is returned when an encrypted value cannot be parsed
+var ErrMalformed = errors.New("malformed encrypted value")
+
+// Encryptor encrypts and decrypts field values and computes blind indexes
+type Encryptor struct {
+	keys KeyProvider
+}
+
+// NewEncryptor creates an encryptor using the given key provider
+func NewEncryptor(keys KeyProvider) *Encryptor {
+	return &Encryptor{
+		keys: keys,
+	}
+}
+
+// Encrypt encrypts a field value. The field name is bound to the ciphertext
+// so values cannot be moved between columns. Empty values stay empty.
+func (e *Encryptor) Encrypt(field, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dek, err := randomKey()
+	if err != nil {
+		return "", err
+	}
+	keyID, wrapped, err := e.keys.WrapKey(dek)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dek, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", field, err)
+	}
+
+	return envelopePrefix + strings.Join([]string{
+		keyID,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(sealed),
+	}, ":"), nil
+}
+
+// Decrypt decrypts a field value produced by Encrypt. Plaintext values are
+// returned unchanged so existing rows keep working until they are rewritten.
+func (e *Encryptor) Decrypt(field, value string) (string, error) {
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return value, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("%w in %s", ErrMalformed, field)
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("%w in %s: %v", ErrMalformed, field, err)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("%w in %s: %v", ErrMalformed, field, err)
+	}
+
+	dek, err := e.keys.UnwrapKey(parts[0], wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, sealed, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a keyed hash of a normalised value that can be stored
+// next to the ciphertext and matched exactly without decrypting
+func (e *Encryptor) BlindIndex(field, value string) string {
+	normalised := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	if normalised == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, e.keys.IndexKey())
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalised))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,223 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keySize is the length of key-encryption, data-encryption and index keys
+const keySize = 32
+
+// KeyProvider wraps and unwraps data keys. The key file is a local stand-in
+// for a KMS; a hosted KMS can be used by implementing the same interface.
+type KeyProvider interface {
+	// WrapKey encrypts a data key with the active master key
+	WrapKey(dek []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key with the master key it was wrapped with
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+
+	// IndexKey returns the key used for blind indexes
+	IndexKey() []byte
+}
+
+// keyFileContents is the on-disk format of a key file
+type keyFileContents struct {
+	ActiveKey string            `json:"active_key"`
+	Keys      map[string][]byte `json:"keys"`
+	IndexKey  []byte            `json:"index_key"`
+}
+
+// KeyFile is a KeyProvider backed by master keys stored in a local JSON file.
+// Rotation adds a new active key; older keys stay in the file so existing
+// values can still be decrypted until they are rewritten.
+type KeyFile struct {
+	contents keyFileContents
+}
+
+// LoadKeyFile reads a key file from disk
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var contents keyFileContents
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+	if _, ok := contents.Keys[contents.ActiveKey]; !ok {
+		return nil, fmt.Errorf("key file %s: active key %q is missing", path, contents.ActiveKey)
+	}
+	for id, key := range contents.Keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key file %s: key %q must be %d bytes", path, id, keySize)
+		}
+	}
+	if len(contents.IndexKey) != keySize {
+		return nil, fmt.Errorf("key file %s: index key must be %d bytes", path, keySize)
+	}
+
+	return &KeyFile{contents: contents}, nil
+}
+
+// CreateKeyFile writes a new key file with a single master key and an index key
+func CreateKeyFile(path string) (*KeyFile, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("key file %s already exists", path)
+	}
+
+	indexKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	kf := &KeyFile{contents: keyFileContents{
+		Keys:     map[string][]byte{},
+		IndexKey: indexKey,
+	}}
+	if _, err := kf.Rotate(); err != nil {
+		return nil, err
+	}
+	if err := kf.Save(path); err != nil {
+		return nil, err
+	}
+	return kf, nil
+}
+
+// Rotate generates a new master key and makes it the active one. The index
+// key is left alone, since changing it would invalidate every blind index.
+func (kf *KeyFile) Rotate() (string, error) {
+	key, err := randomKey()
+	if err != nil {
+		return "", err
+	}
+
+	id := "k" + strconv.Itoa(kf.nextKeyNumber())
+	kf.contents.Keys[id] = key
+	kf.contents.ActiveKey = id
+	return id, nil
+}
+
+// Save writes the key file to disk, readable by the owner only
+func (kf *KeyFile) Save(path string) error {
+	data, err := json.MarshalIndent(kf.contents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key file: %w", err)
+	}
+
+	// Write to a temporary file first so a failed write never loses keys
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace key file: %w", err)
+	}
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (kf *KeyFile) ActiveKeyID() string {
+	return kf.contents.ActiveKey
+}
+
+// KeyIDs returns the IDs of all master keys in the file
+func (kf *KeyFile) KeyIDs() []string {
+	ids := make([]string, 0, len(kf.contents.Keys))
+	for id := range kf.contents.Keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// WrapKey implements KeyProvider
+func (kf *KeyFile) WrapKey(dek []byte) (string, []byte, error) {
+	id := kf.contents.ActiveKey
+	wrapped, err := seal(kf.contents.Keys[id], dek, []byte(id))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return id, wrapped, nil
+}
+
+// UnwrapKey implements KeyProvider
+func (kf *KeyFile) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := kf.contents.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	dek, err := open(key, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+// IndexKey implements KeyProvider
+func (kf *KeyFile) IndexKey() []byte {
+	return kf.contents.IndexKey
+}
+
+// nextKeyNumber returns one more than the highest numbered key ID
+func (kf *KeyFile) nextKeyNumber() int {
+	highest := 0
+	for id := range kf.contents.Keys {
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, "k")); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest + 1
+}
+
+// randomKey generates a new random key
+func randomKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// seal encrypts plaintext with AES-GCM, prefixing the random nonce
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a value produced by seal
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// newGCM creates an AES-GCM cipher for the key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RestoreEmployee brings back a deleted employee
+func (h *Handler) RestoreEmployee(c *fiber.Ctx) error {
+	employee, err := h.svc.Employees.Restore(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, employee.Version)
+	return c.JSON(fiber.Map{
+		"data": employee,
+	})
+}
+
+// RestorePosition brings back a deleted position
+func (h *Handler) RestorePosition(c *fiber.Ctx) error {
+	position, err := h.svc.Org.RestorePosition(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, position.Version)
+	return c.JSON(fiber.Map{
+		"data": position,
+	})
+}
+
+// RestoreDepartment brings back a deleted department
+func (h *Handler) RestoreDepartment(c *fiber.Ctx) error {
+	department, err := h.svc.Org.RestoreDepartment(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, department.Version)
+	return c.JSON(fiber.Map{
+		"data": department,
+	})
+}
+
+// RestoreSite brings back a deleted site
+func (h *Handler) RestoreSite(c *fiber.Ctx) error {
+	site, err := h.svc.Org.RestoreSite(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, site.Version)
+	return c.JSON(fiber.Map{
+		"data": site,
+	})
+}
+
+// ExportEmployee returns everything held about an employee as JSON, or as a
+// ZIP archive with ?format=zip
+func (h *Handler) ExportEmployee(c *fiber.Ctx) error {
+	export, err := h.svc.Privacy.Export(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	switch c.Query("format", "json") {
+	case "json":
+		return c.JSON(fiber.Map{
+			"data": export,
+		})
+	case "zip":
+		c.Set(fiber.HeaderContentType, "application/zip")
+		c.Attachment(fmt.Sprintf("employee-%s.zip", export.Employee.ID))
+		return export.WriteZip(c.Response().BodyWriter())
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "format must be json or zip")
+	}
+}
+
+// AnonymiseEmployee erases an employee's personal data
+func (h *Handler) AnonymiseEmployee(c *fiber.Ctx) error {
+	if err := h.svc.Privacy.Anonymise(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// dateLayout is the wire format for calendar dates
+const dateLayout = "2006-01-02"
+
+// employeeRequest is the request body for creating or updating an employee
+type employeeRequest struct {
+	FirstName      string `json:"first_name"`
+	MiddleName     string `json:"middle_name"`
+	LastName       string `json:"last_name"`
+	DisplayName    string `json:"display_name"`
+	Email          string `json:"email"`
+	Address        string `json:"address"`
+	PositionID     string `json:"position_id"`
+	DepartmentID   string `json:"department_id"`
+	SiteID         string `json:"site_id"`
+	ManagerID      string `json:"manager_id"`
+	EmploymentType string `json:"employment_type"`
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	Status         string `json:"status"`
+	ProfilePicture string `json:"profile_picture_url"`
+	Version        int    `json:"version"`
+}
+
+// toEmployee converts the request into a repository employee
+func (r employeeRequest) toEmployee() (*repository.Employee, error) {
+	employee := &repository.Employee{
+		FirstName:      r.FirstName,
+		MiddleName:     r.MiddleName,
+		LastName:       r.LastName,
+		DisplayName:    r.DisplayName,
+		Email:          r.Email,
+		Address:        r.Address,
+		PositionID:     r.PositionID,
+		DepartmentID:   r.DepartmentID,
+		SiteID:         r.SiteID,
+		ManagerID:      r.ManagerID,
+		EmploymentType: r.EmploymentType,
+		Status:         r.Status,
+		ProfilePicture: r.ProfilePicture,
+		Version:        r.Version,
+	}
+
+	var err error
+	if employee.StartDate, err = parseDate(r.StartDate); err != nil {
+		return nil, services.NewValidationError("start_date", "must be a date in YYYY-MM-DD format")
+	}
+	if employee.EndDate, err = parseDate(r.EndDate); err != nil {
+		return nil, services.NewValidationError("end_date", "must be a date in YYYY-MM-DD format")
+	}
+	return employee, nil
+}
+
+// parseDate parses an optional calendar date
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(dateLayout, value)
+}
+
+// GetEmployees returns a list of employees
+func (h *Handler) GetEmployees(c *fiber.Ctx) error {
+	limit, offset := pageParams(c)
+	filter := services.EmployeeFilter{
+		DepartmentID: c.Query("department_id"),
+		SiteID:       c.Query("site_id"),
+		PositionID:   c.Query("position_id"),
+		ManagerID:    c.Query("manager_id"),
+		Status:       c.Query("status"),
+		Address:      c.Query("address"),
+	}
+
+	employees, total, err := h.svc.Employees.List(c.UserContext(), filter, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return listResponse(c, employees, total, limit, offset)
+}
+
+// GetEmployee returns a single employee by ID
+func (h *Handler) GetEmployee(c *fiber.Ctx) error {
+	employee, err := h.svc.Employees.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, employee.Version)
+	return c.JSON(fiber.Map{
+		"data": employee,
+	})
+}
+
+// CreateEmployee creates a new employee
+func (h *Handler) CreateEmployee(c *fiber.Ctx) error {
+	var req employeeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	employee, err := req.toEmployee()
+	if err != nil {
+		return err
+	}
+
+	created, err := h.svc.Employees.Create(c.UserContext(), employee)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, created.Version)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
+
+// UpdateEmployee replaces an employee's details
+func (h *Handler) UpdateEmployee(c *fiber.Ctx) error {
+	var req employeeRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	employee, err := req.toEmployee()
+	if err != nil {
+		return err
+	}
+	employee.ID = c.Params("id")
+	if employee.Version, err = expectedVersion(c, employee.Version); err != nil {
+		return err
+	}
+
+	updated, err := h.svc.Employees.Update(c.UserContext(), employee)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(fiber.Map{
+		"data": updated,
+	})
+}
+
+// DeleteEmployee deletes an employee
+func (h *Handler) DeleteEmployee(c *fiber.Ctx) error {
+	if err := h.svc.Employees.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetDirectReports returns the employees reporting to an employee
+func (h *Handler) GetDirectReports(c *fiber.Ctx) error {
+	employees, err := h.svc.Employees.DirectReports(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": employees,
+	})
+}
@@ -1,90 +1,196 @@
 package handlers
 
 import (
-	"github.com/gfurduy/byebob/config"
-	"github.com/gfurduy/byebob/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gfurduy/byebob/internal/middleware"
+	"github.com/gfurduy/byebob/internal/services"
 	"github.com/gfurduy/byebob/internal/templates"
 	"github.com/gofiber/fiber/v2"
 )
 
 // Handler manages the application's HTTP handlers
 type Handler struct {
-	repo repository.Repository
+	svc *services.Services
 }
 
-// NewHandler creates a new handler with the given repository
-func NewHandler(repo repository.Repository) *Handler {
+// NewHandler creates a new handler with the given services
+func NewHandler(svc *services.Services) *Handler {
 	return &Handler{
-		repo: repo,
+		svc: svc,
 	}
 }
 
 // SetupRoutes configures all application routes
-func SetupRoutes(app *fiber.App, repo repository.Repository) {
-	// Create a handler with the repository
-	h := NewHandler(repo)
+func SetupRoutes(app *fiber.App, svc *services.Services) {
+	// Create a handler with the services
+	h := NewHandler(svc)
 
 	// Web routes (HTML)
 	app.Get("/", HomeHandler)
 
+	profile := app.Group("/profile", middleware.Tenant(svc.Tenants), middleware.ReadYourWrites())
+	profile.Get("/", h.MyProfilePage)
+	profile.Get("/:id", h.ProfilePage)
+	profile.Post("/:id", h.UpdateProfileForm)
+	profile.Post("/:id/picture", h.UploadPictureForm)
+	profile.Post("/:id/picture/remove", h.RemovePictureForm)
+	profile.Post("/:id/change-requests", h.RequestChangeForm)
+	profile.Post("/:id/change-requests/:requestID/approve", h.ApproveChangeForm)
+	profile.Post("/:id/change-requests/:requestID/reject", h.RejectChangeForm)
+
 	// API v1 routes
 	api := app.Group("/api")
 	v1 := api.Group("/v1")
 
-	// Health check
-	v1.Get("/health", HealthCheck)
+	// Everything below is scoped to the tenant and acting user of the request;
+	// the acting user is identified for the whole app, see Authenticator
+	v1.Use(middleware.Tenant(svc.Tenants), middleware.ReadYourWrites())
 
-	// Employee routes
+	// Directory search
+	v1.Get("/search", h.SearchEmployees)
+
+	// Employee routes. Only HR edits employee records directly; employees
+	// change their own through the profile and change request routes.
 	employees := v1.Group("/employees")
 	employees.Get("/", h.GetEmployees)
+	employees.Post("/", middleware.RequireHR(), h.CreateEmployee)
 	employees.Get("/:id", h.GetEmployee)
-	// Add more employee routes as needed
+	employees.Put("/:id", middleware.RequireHR(), h.UpdateEmployee)
+	employees.Delete("/:id", middleware.RequireHR(), h.DeleteEmployee)
+	employees.Get("/:id/reports", h.GetDirectReports)
+	employees.Get("/:id/goals", h.GetEmployeeGoals)
+	employees.Get("/:id/profile", h.GetProfile)
+	employees.Patch("/:id/profile", h.UpdateProfile)
+	employees.Post("/:id/picture", h.UploadPicture)
+	employees.Delete("/:id/picture", h.DeletePicture)
+	employees.Get("/:id/change-requests", h.GetEmployeeChangeRequests)
+	employees.Post("/:id/change-requests", h.CreateChangeRequest)
+
+	// Organisation structure routes; only HR changes the structure
+	positions := v1.Group("/positions")
+	positions.Get("/", h.GetPositions)
+	positions.Post("/", middleware.RequireHR(), h.CreatePosition)
+	positions.Get("/:id", h.GetPosition)
+	positions.Put("/:id", middleware.RequireHR(), h.UpdatePosition)
+	positions.Delete("/:id", middleware.RequireHR(), h.DeletePosition)
+
+	departments := v1.Group("/departments")
+	departments.Get("/", h.GetDepartments)
+	departments.Post("/", middleware.RequireHR(), h.CreateDepartment)
+	departments.Get("/:id", h.GetDepartment)
+	departments.Put("/:id", middleware.RequireHR(), h.UpdateDepartment)
+	departments.Delete("/:id", middleware.RequireHR(), h.DeleteDepartment)
+
+	sites := v1.Group("/sites")
+	sites.Get("/", h.GetSites)
+	sites.Post("/", middleware.RequireHR(), h.CreateSite)
+	sites.Get("/:id", h.GetSite)
+	sites.Put("/:id", middleware.RequireHR(), h.UpdateSite)
+	sites.Delete("/:id", middleware.RequireHR(), h.DeleteSite)
+
+	// Assessment routes
+	assessments := v1.Group("/assessments")
+	assessments.Get("/", h.GetAssessments)
+	assessments.Post("/", h.CreateAssessment)
+	assessments.Get("/:id", h.GetAssessment)
+	assessments.Put("/:id", h.UpdateAssessment)
+	assessments.Post("/:id/status", h.TransitionAssessment)
+	assessments.Delete("/:id", h.DeleteAssessment)
+
+	// Goal routes
+	goals := v1.Group("/goals")
+	goals.Post("/", h.CreateGoal)
+	goals.Get("/:id", h.GetGoal)
+	goals.Put("/:id", h.UpdateGoal)
+	goals.Delete("/:id", h.DeleteGoal)
+	goals.Get("/:id/checkins", h.GetGoalCheckIns)
+	goals.Post("/:id/checkins", h.CreateGoalCheckIn)
+
+	// Admin routes
+	admin := v1.Group("/admin", middleware.RequireHR())
+	admin.Post("/employees/:id/restore", h.RestoreEmployee)
+	admin.Get("/employees/:id/export", h.ExportEmployee)
+	admin.Post("/employees/:id/anonymise", h.AnonymiseEmployee)
+	admin.Post("/positions/:id/restore", h.RestorePosition)
+	admin.Post("/departments/:id/restore", h.RestoreDepartment)
+	admin.Post("/sites/:id/restore", h.RestoreSite)
+	admin.Get("/change-requests", h.GetChangeRequests)
+	admin.Post("/change-requests/:id/approve", h.ApproveChangeRequest)
+	admin.Post("/change-requests/:id/reject", h.RejectChangeRequest)
 }
 
 // HomeHandler renders the home page
 func HomeHandler(c *fiber.Ctx) error {
-	return templates.Home().Render(c.Context(), c.Response().BodyWriter())
+	return render(c, templates.Home())
 }
 
-// HealthCheck handler for health endpoint
-func HealthCheck(c *fiber.Ctx) error {
-	cfg, _ := config.NewConfig()
-	return c.JSON(fiber.Map{
-		"status":      "ok",
-		"message":     "ByeBob API is healthy",
-		"environment": cfg.Environment,
-		"version":     "dev", // This would come from the version in main
-	})
+// render writes a page or fragment as HTML, with the request's CSRF token for its htmx
+// requests
+func render(c *fiber.Ctx, page templ.Component) error {
+	ctx := templates.WithCSRFToken(c.UserContext(), middleware.CSRFToken(c))
+	c.Type("html", "utf-8")
+	return page.Render(ctx, c.Response().BodyWriter())
 }
 
-// GetEmployees returns a list of employees
-func (h *Handler) GetEmployees(c *fiber.Ctx) error {
-	employees, err := h.repo.GetEmployees(c.Context())
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Error fetching employees",
-		})
+// parseBody decodes the JSON request body, reporting malformed input as a 400
+func parseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body: "+err.Error())
 	}
-	
+	return nil
+}
+
+// pageParams reads the limit and offset query parameters
+func pageParams(c *fiber.Ctx) (int, int) {
+	return services.NormalizePage(c.QueryInt("limit", services.DefaultPageSize), c.QueryInt("offset", 0))
+}
+
+// listResponse writes a page of results with its pagination metadata
+func listResponse(c *fiber.Ctx, data interface{}, total int64, limit, offset int) error {
 	return c.JSON(fiber.Map{
-		"data": employees,
+		"data":   data,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
 	})
 }
 
-// GetEmployee returns a single employee by ID
-func (h *Handler) GetEmployee(c *fiber.Ctx) error {
-	id := c.Params("id")
-	
-	employee, err := h.repo.GetEmployeeByID(c.Context(), id)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Error fetching employee",
-		})
+// setETag exposes a record version as a strong entity tag
+func setETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, strconv.Quote(strconv.Itoa(version)))
+}
+
+// expectedVersion returns the version an update is based on, taken from the
+// If-Match header or, failing that, the version in the request body.
+// If-Match: * updates whatever version is current.
+func expectedVersion(c *fiber.Ctx, bodyVersion int) (int, error) {
+	ifMatch := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if ifMatch == "" {
+		if bodyVersion > 0 {
+			return bodyVersion, nil
+		}
+		return 0, fiber.NewError(fiber.StatusPreconditionRequired, "updates require an If-Match header with the current ETag")
 	}
-	
-	return c.JSON(fiber.Map{
-		"data": employee,
-	})
-} 
\ No newline at end of file
+	if ifMatch == "*" {
+		return services.AnyVersion, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(ifMatch, `"`))
+	if err != nil || version <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "If-Match must be an ETag returned by this API")
+	}
+	return version, nil
+}
+
+// formVersion returns the version a web form was rendered with. Only
+// If-Match may ask for services.AnyVersion, so any other number that is not
+// positive counts as missing.
+func formVersion(version int) int {
+	if version < 0 {
+		return 0
+	}
+	return version
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gfurduy/byebob/internal/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+// readinessTimeout bounds the checks of one readiness probe
+const readinessTimeout = 5 * time.Second
+
+// BuildInfo identifies the running build in probe responses
+type BuildInfo struct {
+	Version   string `json:"version"`
+	BuildTime string `json:"build_time"`
+}
+
+// Livez returns a handler reporting that the process is up. It checks no
+// dependencies, so an orchestrator only restarts the server when it is stuck.
+func Livez(build BuildInfo) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":     health.StatusOK,
+			"version":    build.Version,
+			"build_time": build.BuildTime,
+		})
+	}
+}
+
+// Readyz returns a handler reporting whether the server can serve traffic,
+// with the outcome of each check. It responds 503 when any check fails.
+func Readyz(checker *health.Checker, build BuildInfo) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+		defer cancel()
+		report := checker.Run(ctx)
+
+		status := fiber.StatusOK
+		if report.Status != health.StatusOK {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"status":     report.Status,
+			"version":    build.Version,
+			"build_time": build.BuildTime,
+			"checks":     report.Checks,
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gfurduy/byebob/internal/storage"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Media serves blobs, such as profile picture thumbnails, from the blob
+// store by key. Keys change with their content, so responses are cached for
+// good.
+func Media(blobs storage.BlobStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body, info, err := blobs.Get(c.UserContext(), c.Params("*"))
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return fiber.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderCacheControl, cacheImmutable)
+		c.Set(fiber.HeaderContentType, info.ContentType)
+		return c.SendStream(body, int(info.Size))
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// replicaStats reports the read replica pool and its replication lag
+type replicaStats struct {
+	repository.PoolStats
+	Healthy    bool    `json:"healthy"`
+	LagSeconds float64 `json:"lag_seconds"`
+}
+
+// DBStats returns a handler reporting connection pool statistics and query
+// timings per repository method
+func DBStats(db *repository.DBPool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		primary, replica, since := db.PoolStats()
+		body := fiber.Map{
+			"since":   since,
+			"pool":    primary,
+			"queries": db.Tracer.Stats(),
+		}
+		if replica != nil {
+			body["replica"] = replicaStats{
+				PoolStats:  *replica,
+				Healthy:    db.Replica.Healthy(),
+				LagSeconds: db.Replica.Lag().Seconds(),
+			}
+		}
+		return c.JSON(body)
+	}
+}
+
+// ResetDBStats returns a handler that restarts the pool and query counters
+func ResetDBStats(db *repository.DBPool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		db.ResetStats()
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
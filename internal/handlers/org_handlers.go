@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetPositions returns a list of positions
+func (h *Handler) GetPositions(c *fiber.Ctx) error {
+	limit, offset := pageParams(c)
+	positions, total, err := h.svc.Org.ListPositions(c.UserContext(), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return listResponse(c, positions, total, limit, offset)
+}
+
+// GetPosition returns a single position by ID
+func (h *Handler) GetPosition(c *fiber.Ctx) error {
+	position, err := h.svc.Org.GetPosition(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, position.Version)
+	return c.JSON(fiber.Map{
+		"data": position,
+	})
+}
+
+// CreatePosition creates a new position
+func (h *Handler) CreatePosition(c *fiber.Ctx) error {
+	var position repository.Position
+	if err := parseBody(c, &position); err != nil {
+		return err
+	}
+
+	created, err := h.svc.Org.CreatePosition(c.UserContext(), &position)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, created.Version)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
+
+// UpdatePosition replaces a position's details
+func (h *Handler) UpdatePosition(c *fiber.Ctx) error {
+	var position repository.Position
+	if err := parseBody(c, &position); err != nil {
+		return err
+	}
+	position.ID = c.Params("id")
+	version, err := expectedVersion(c, position.Version)
+	if err != nil {
+		return err
+	}
+	position.Version = version
+
+	updated, err := h.svc.Org.UpdatePosition(c.UserContext(), &position)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(fiber.Map{
+		"data": updated,
+	})
+}
+
+// DeletePosition deletes a position
+func (h *Handler) DeletePosition(c *fiber.Ctx) error {
+	if err := h.svc.Org.DeletePosition(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetDepartments returns a list of departments
+func (h *Handler) GetDepartments(c *fiber.Ctx) error {
+	limit, offset := pageParams(c)
+	departments, total, err := h.svc.Org.ListDepartments(c.UserContext(), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return listResponse(c, departments, total, limit, offset)
+}
+
+// GetDepartment returns a single department by ID
+func (h *Handler) GetDepartment(c *fiber.Ctx) error {
+	department, err := h.svc.Org.GetDepartment(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, department.Version)
+	return c.JSON(fiber.Map{
+		"data": department,
+	})
+}
+
+// CreateDepartment creates a new department
+func (h *Handler) CreateDepartment(c *fiber.Ctx) error {
+	var department repository.Department
+	if err := parseBody(c, &department); err != nil {
+		return err
+	}
+
+	created, err := h.svc.Org.CreateDepartment(c.UserContext(), &department)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, created.Version)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
+
+// UpdateDepartment replaces a department's details
+func (h *Handler) UpdateDepartment(c *fiber.Ctx) error {
+	var department repository.Department
+	if err := parseBody(c, &department); err != nil {
+		return err
+	}
+	department.ID = c.Params("id")
+	version, err := expectedVersion(c, department.Version)
+	if err != nil {
+		return err
+	}
+	department.Version = version
+
+	updated, err := h.svc.Org.UpdateDepartment(c.UserContext(), &department)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(fiber.Map{
+		"data": updated,
+	})
+}
+
+// DeleteDepartment deletes a department
+func (h *Handler) DeleteDepartment(c *fiber.Ctx) error {
+	if err := h.svc.Org.DeleteDepartment(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetSites returns a list of sites
+func (h *Handler) GetSites(c *fiber.Ctx) error {
+	limit, offset := pageParams(c)
+	sites, total, err := h.svc.Org.ListSites(c.UserContext(), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return listResponse(c, sites, total, limit, offset)
+}
+
+// GetSite returns a single site by ID
+func (h *Handler) GetSite(c *fiber.Ctx) error {
+	site, err := h.svc.Org.GetSite(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, site.Version)
+	return c.JSON(fiber.Map{
+		"data": site,
+	})
+}
+
+// CreateSite creates a new site
+func (h *Handler) CreateSite(c *fiber.Ctx) error {
+	var site repository.Site
+	if err := parseBody(c, &site); err != nil {
+		return err
+	}
+
+	created, err := h.svc.Org.CreateSite(c.UserContext(), &site)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, created.Version)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
+
+// UpdateSite replaces a site's details
+func (h *Handler) UpdateSite(c *fiber.Ctx) error {
+	var site repository.Site
+	if err := parseBody(c, &site); err != nil {
+		return err
+	}
+	site.ID = c.Params("id")
+	version, err := expectedVersion(c, site.Version)
+	if err != nil {
+		return err
+	}
+	site.Version = version
+
+	updated, err := h.svc.Org.UpdateSite(c.UserContext(), &site)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(fiber.Map{
+		"data": updated,
+	})
+}
+
+// DeleteSite deletes a site
+func (h *Handler) DeleteSite(c *fiber.Ctx) error {
+	if err := h.svc.Org.DeleteSite(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAssessments returns a list of assessments
+func (h *Handler) GetAssessments(c *fiber.Ctx) error {
+	limit, offset := pageParams(c)
+	filter := services.AssessmentFilter{
+		EmployeeID: c.Query("employee_id"),
+		ReviewerID: c.Query("reviewer_id"),
+		Status:     c.Query("status"),
+	}
+
+	assessments, total, err := h.svc.Assessments.List(c.UserContext(), filter, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return listResponse(c, assessments, total, limit, offset)
+}
+
+// GetAssessment returns a single assessment by ID
+func (h *Handler) GetAssessment(c *fiber.Ctx) error {
+	assessment, err := h.svc.Assessments.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, assessment.Version)
+	return c.JSON(fiber.Map{
+		"data": assessment,
+	})
+}
+
+// CreateAssessment opens a new assessment
+func (h *Handler) CreateAssessment(c *fiber.Ctx) error {
+	var assessment repository.Assessment
+	if err := parseBody(c, &assessment); err != nil {
+		return err
+	}
+
+	created, err := h.svc.Assessments.Create(c.UserContext(), &assessment)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, created.Version)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
+
+// UpdateAssessment changes the template or reviewer of an assessment
+func (h *Handler) UpdateAssessment(c *fiber.Ctx) error {
+	var assessment repository.Assessment
+	if err := parseBody(c, &assessment); err != nil {
+		return err
+	}
+	assessment.ID = c.Params("id")
+	version, err := expectedVersion(c, assessment.Version)
+	if err != nil {
+		return err
+	}
+	assessment.Version = version
+
+	updated, err := h.svc.Assessments.Update(c.UserContext(), &assessment)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(fiber.Map{
+		"data": updated,
+	})
+}
+
+// TransitionAssessment moves an assessment to a new status
+func (h *Handler) TransitionAssessment(c *fiber.Ctx) error {
+	var req struct {
+		Status  string `json:"status"`
+		Version int    `json:"version"`
+	}
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	version, err := expectedVersion(c, req.Version)
+	if err != nil {
+		return err
+	}
+
+	assessment, err := h.svc.Assessments.Transition(c.UserContext(), c.Params("id"), req.Status, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, assessment.Version)
+	return c.JSON(fiber.Map{
+		"data": assessment,
+	})
+}
+
+// DeleteAssessment deletes an assessment
+func (h *Handler) DeleteAssessment(c *fiber.Ctx) error {
+	if err := h.svc.Assessments.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetEmployeeGoals returns the goals of an employee
+func (h *Handler) GetEmployeeGoals(c *fiber.Ctx) error {
+	goals, err := h.svc.Goals.ListByEmployee(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": goals,
+	})
+}
+
+// GetGoal returns a single goal by ID
+func (h *Handler) GetGoal(c *fiber.Ctx) error {
+	goal, err := h.svc.Goals.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, goal.Version)
+	return c.JSON(fiber.Map{
+		"data": goal,
+	})
+}
+
+// CreateGoal creates a new goal
+func (h *Handler) CreateGoal(c *fiber.Ctx) error {
+	var goal repository.Goal
+	if err := parseBody(c, &goal); err != nil {
+		return err
+	}
+
+	created, err := h.svc.Goals.Create(c.UserContext(), &goal)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, created.Version)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
+
+// UpdateGoal replaces a goal's details
+func (h *Handler) UpdateGoal(c *fiber.Ctx) error {
+	var goal repository.Goal
+	if err := parseBody(c, &goal); err != nil {
+		return err
+	}
+	goal.ID = c.Params("id")
+	version, err := expectedVersion(c, goal.Version)
+	if err != nil {
+		return err
+	}
+	goal.Version = version
+
+	updated, err := h.svc.Goals.Update(c.UserContext(), &goal)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, updated.Version)
+	return c.JSON(fiber.Map{
+		"data": updated,
+	})
+}
+
+// DeleteGoal deletes a goal and its check-ins
+func (h *Handler) DeleteGoal(c *fiber.Ctx) error {
+	if err := h.svc.Goals.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetGoalCheckIns returns the check-ins recorded on a goal
+func (h *Handler) GetGoalCheckIns(c *fiber.Ctx) error {
+	checkIns, err := h.svc.Goals.ListCheckIns(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": checkIns,
+	})
+}
+
+// CreateGoalCheckIn records progress on a goal
+func (h *Handler) CreateGoalCheckIn(c *fiber.Ctx) error {
+	var checkIn repository.GoalCheckIn
+	if err := parseBody(c, &checkIn); err != nil {
+		return err
+	}
+	checkIn.GoalID = c.Params("id")
+
+	created, err := h.svc.Goals.AddCheckIn(c.UserContext(), &checkIn)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": created,
+	})
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/middleware"
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gfurduy/byebob/internal/templates"
+	"github.com/gofiber/fiber/v2"
+)
+
+// profileRequest is the request body for a self-service profile edit
+type profileRequest struct {
+	DisplayName    string `json:"display_name" form:"display_name"`
+	Address        string `json:"address" form:"address"`
+	ProfilePicture string `json:"profile_picture_url" form:"profile_picture_url"`
+	Version        int    `json:"version" form:"version"`
+}
+
+// toUpdate converts the request into a profile update
+func (r profileRequest) toUpdate(version int) services.ProfileUpdate {
+	return services.ProfileUpdate{
+		DisplayName:    r.DisplayName,
+		Address:        r.Address,
+		ProfilePicture: r.ProfilePicture,
+		Version:        version,
+	}
+}
+
+// changeRequestBody is the request body for a sensitive field change request
+type changeRequestBody struct {
+	Changes map[string]string `json:"changes"`
+	Reason  string            `json:"reason"`
+}
+
+// changeRequestForm is the form posted from the profile page to request a
+// sensitive field change. Every field is sent; unchanged ones are dropped.
+type changeRequestForm struct {
+	EmploymentType string `form:"employment_type"`
+	StartDate      string `form:"start_date"`
+	EndDate        string `form:"end_date"`
+	Status         string `form:"status"`
+	Reason         string `form:"reason"`
+}
+
+// reviewRequest is the request body for approving or rejecting a change request
+type reviewRequest struct {
+	Note string `json:"note" form:"note"`
+}
+
+// reviewFunc approves or rejects a change request
+type reviewFunc func(ctx context.Context, id, note string) (*repository.EmployeeChangeRequest, error)
+
+// GetProfile returns an employee with their place in the organisation
+func (h *Handler) GetProfile(c *fiber.Ctx) error {
+	profile, err := h.svc.Profiles.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": profile,
+	})
+}
+
+// UpdateProfile changes the fields an employee maintains themselves
+func (h *Handler) UpdateProfile(c *fiber.Ctx) error {
+	var req profileRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+	version, err := expectedVersion(c, req.Version)
+	if err != nil {
+		return err
+	}
+
+	employee, err := h.svc.Profiles.Update(c.UserContext(), c.Params("id"), req.toUpdate(version))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, employee.Version)
+	return c.JSON(fiber.Map{
+		"data": employee,
+	})
+}
+
+// GetEmployeeChangeRequests returns the change requests for an employee, newest first
+func (h *Handler) GetEmployeeChangeRequests(c *fiber.Ctx) error {
+	requests, err := h.svc.Profiles.ChangeRequests(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": requests,
+	})
+}
+
+// CreateChangeRequest asks HR to change sensitive fields of an employee
+func (h *Handler) CreateChangeRequest(c *fiber.Ctx) error {
+	var req changeRequestBody
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	request, err := h.svc.Profiles.RequestChange(c.UserContext(), c.Params("id"), req.Changes, req.Reason)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": request,
+	})
+}
+
+// GetChangeRequests returns a page of change requests, filtered by status
+func (h *Handler) GetChangeRequests(c *fiber.Ctx) error {
+	limit, offset := pageParams(c)
+	requests, total, err := h.svc.Profiles.ListChangeRequests(c.UserContext(), c.Query("status"), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return listResponse(c, requests, total, limit, offset)
+}
+
+// ApproveChangeRequest applies a pending change request
+func (h *Handler) ApproveChangeRequest(c *fiber.Ctx) error {
+	return h.reviewChangeRequest(c, h.svc.Profiles.ApproveChangeRequest)
+}
+
+// RejectChangeRequest closes a pending change request without applying it
+func (h *Handler) RejectChangeRequest(c *fiber.Ctx) error {
+	return h.reviewChangeRequest(c, h.svc.Profiles.RejectChangeRequest)
+}
+
+// reviewChangeRequest approves or rejects a change request with an optional note
+func (h *Handler) reviewChangeRequest(c *fiber.Ctx, review reviewFunc) error {
+	var req reviewRequest
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &req); err != nil {
+			return err
+		}
+	}
+
+	request, err := review(c.UserContext(), c.Params("id"), req.Note)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, request.Version)
+	return c.JSON(fiber.Map{
+		"data": request,
+	})
+}
+
+// MyProfilePage renders the profile of the acting user
+func (h *Handler) MyProfilePage(c *fiber.Ctx) error {
+	actor := middleware.CurrentActor(c)
+	if actor.EmployeeID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "sign in to see your profile")
+	}
+	return h.profilePage(c, actor.EmployeeID)
+}
+
+// ProfilePage renders the profile of an employee
+func (h *Handler) ProfilePage(c *fiber.Ctx) error {
+	return h.profilePage(c, c.Params("id"))
+}
+
+// UpdateProfileForm saves the profile edit form and re-renders the profile
+func (h *Handler) UpdateProfileForm(c *fiber.Ctx) error {
+	var req profileRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	_, err := h.svc.Profiles.Update(c.UserContext(), c.Params("id"), req.toUpdate(formVersion(req.Version)))
+	return h.profileFormResult(c, c.Params("id"), "Your profile has been saved.", err)
+}
+
+// RequestChangeForm submits the change request form and re-renders the profile
+func (h *Handler) RequestChangeForm(c *fiber.Ctx) error {
+	var form changeRequestForm
+	if err := parseBody(c, &form); err != nil {
+		return err
+	}
+
+	changes := map[string]string{
+		"employment_type": form.EmploymentType,
+		"start_date":      form.StartDate,
+		"end_date":        form.EndDate,
+		"status":          form.Status,
+	}
+	_, err := h.svc.Profiles.RequestChange(c.UserContext(), c.Params("id"), changes, form.Reason)
+	return h.profileFormResult(c, c.Params("id"), "Your request has been sent to HR for approval.", err)
+}
+
+// ApproveChangeForm approves a change request from the profile page
+func (h *Handler) ApproveChangeForm(c *fiber.Ctx) error {
+	return h.reviewChangeForm(c, h.svc.Profiles.ApproveChangeRequest, "The change has been approved and applied.")
+}
+
+// RejectChangeForm rejects a change request from the profile page
+func (h *Handler) RejectChangeForm(c *fiber.Ctx) error {
+	return h.reviewChangeForm(c, h.svc.Profiles.RejectChangeRequest, "The change has been rejected.")
+}
+
+// reviewChangeForm reviews a change request and re-renders the profile
+func (h *Handler) reviewChangeForm(c *fiber.Ctx, review reviewFunc, success string) error {
+	var req reviewRequest
+	if err := parseBody(c, &req); err != nil {
+		return err
+	}
+
+	_, err := review(c.UserContext(), c.Params("requestID"), req.Note)
+	return h.profileFormResult(c, c.Params("id"), success, err)
+}
+
+// profilePage renders the full profile page of an employee
+func (h *Handler) profilePage(c *fiber.Ctx, id string) error {
+	profile, err := h.svc.Profiles.Get(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	return render(c, templates.ProfilePage(h.profileView(c, profile, templates.Notice{})))
+}
+
+// profileFormResult re-renders the profile after a form submission with its
+// outcome. Errors the user can act on are shown on the page with their
+// status; anything else goes to the error handler.
+func (h *Handler) profileFormResult(c *fiber.Ctx, id, success string, err error) error {
+	notice := templates.Notice{Success: success}
+	if err != nil {
+		status := formErrorStatus(err)
+		if status == 0 {
+			return err
+		}
+		c.Status(status)
+		notice = templates.Notice{Error: formErrorMessage(err), Fields: services.FieldErrorsOf(err)}
+	}
+
+	profile, perr := h.svc.Profiles.Get(c.UserContext(), id)
+	if perr != nil {
+		return perr
+	}
+	return render(c, templates.ProfileContent(h.profileView(c, profile, notice)))
+}
+
+// profileView assembles what the profile templates render
+func (h *Handler) profileView(c *fiber.Ctx, profile *services.Profile, notice templates.Notice) templates.ProfileView {
+	return templates.ProfileView{
+		Profile:  profile,
+		Reviewer: middleware.CurrentActor(c).HR,
+		Notice:   notice,
+	}
+}
+
+// formErrorStatus returns the status of an error a form can report to the
+// user, or zero for errors it cannot
+func formErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrConflict):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrForbidden):
+		return fiber.StatusForbidden
+	}
+	return 0
+}
+
+// formErrorMessage summarises a form error for the user
+func formErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		return "Some fields are invalid."
+	case errors.Is(err, services.ErrConflict):
+		if len(services.FieldErrorsOf(err)) > 0 {
+			return "The change could not be saved."
+		}
+		return "The profile has changed since you opened it. Reload the page and try again."
+	}
+	return "You are not allowed to make this change."
+}
+
+// UploadPicture replaces an employee's profile picture with the image
+// uploaded in the multipart "picture" field
+func (h *Handler) UploadPicture(c *fiber.Ctx) error {
+	data, err := pictureUpload(c)
+	if err != nil {
+		return err
+	}
+	bodyVersion, _ := strconv.Atoi(c.FormValue("version"))
+	version, err := expectedVersion(c, bodyVersion)
+	if err != nil {
+		return err
+	}
+
+	employee, err := h.svc.Profiles.UploadPicture(c.UserContext(), c.Params("id"), data, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, employee.Version)
+	return c.JSON(fiber.Map{
+		"data": employee,
+	})
+}
+
+// DeletePicture clears an employee's profile picture
+func (h *Handler) DeletePicture(c *fiber.Ctx) error {
+	version, err := expectedVersion(c, 0)
+	if err != nil {
+		return err
+	}
+
+	employee, err := h.svc.Profiles.RemovePicture(c.UserContext(), c.Params("id"), version)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, employee.Version)
+	return c.JSON(fiber.Map{
+		"data": employee,
+	})
+}
+
+// UploadPictureForm saves a picture uploaded from the profile page and
+// re-renders the profile
+func (h *Handler) UploadPictureForm(c *fiber.Ctx) error {
+	data, err := pictureUpload(c)
+	if err == nil {
+		version, _ := strconv.Atoi(c.FormValue("version"))
+		_, err = h.svc.Profiles.UploadPicture(c.UserContext(), c.Params("id"), data, formVersion(version))
+	}
+	return h.profileFormResult(c, c.Params("id"), "Your picture has been updated.", err)
+}
+
+// RemovePictureForm clears the picture from the profile page and re-renders
+// the profile
+func (h *Handler) RemovePictureForm(c *fiber.Ctx) error {
+	version, _ := strconv.Atoi(c.FormValue("version"))
+	_, err := h.svc.Profiles.RemovePicture(c.UserContext(), c.Params("id"), formVersion(version))
+	return h.profileFormResult(c, c.Params("id"), "Your picture has been removed.", err)
+}
+
+// IsUpload reports whether a request uploads a profile picture, which may be
+// larger than other request bodies
+func IsUpload(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodPost && strings.HasSuffix(c.Path(), "/picture")
+}
+
+// pictureUpload reads the file in the multipart "picture" field
+func pictureUpload(c *fiber.Ctx) ([]byte, error) {
+	header, err := c.FormFile("picture")
+	if err != nil {
+		return nil, services.NewValidationError("picture", "is required")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	return data, nil
+}
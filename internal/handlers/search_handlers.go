@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gfurduy/byebob/internal/templates"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SearchEmployees searches the employee directory. htmx requests from the
+// header type-ahead receive the rendered results instead of JSON, and an
+// empty query clears them.
+func (h *Handler) SearchEmployees(c *fiber.Ctx) error {
+	query := c.Query("q")
+	htmx := c.Get("HX-Request") == "true"
+	if htmx && strings.TrimSpace(query) == "" {
+		return render(c, templates.SearchResults("", nil))
+	}
+
+	results, err := h.svc.Search.Employees(c.UserContext(), query, c.QueryInt("limit", services.DefaultSearchLimit))
+	if err != nil {
+		return err
+	}
+
+	if htmx {
+		return render(c, templates.SearchResults(query, results))
+	}
+	return c.JSON(fiber.Map{
+		"data": results,
+	})
+}
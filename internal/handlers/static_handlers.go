@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"path"
+
+	"github.com/gfurduy/byebob/static"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cache policies of static assets. A fingerprinted URL changes with its
+// content, so it is cached for good; a plain URL must be revalidated.
+const (
+	cacheImmutable  = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
+// StaticAssets serves the assets in the manifest, under their fingerprinted
+// or their plain path
+func StaticAssets() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name, fingerprinted, ok := static.Lookup(c.Params("*"))
+		if !ok {
+			return fiber.ErrNotFound
+		}
+		data, err := static.FS.ReadFile(name)
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+
+		etag := `"` + static.Fingerprint(name) + `"`
+		c.Set(fiber.HeaderETag, etag)
+		if fingerprinted {
+			c.Set(fiber.HeaderCacheControl, cacheImmutable)
+		} else {
+			c.Set(fiber.HeaderCacheControl, cacheRevalidate)
+		}
+		if c.Get(fiber.HeaderIfNoneMatch) == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		c.Type(path.Ext(name))
+		return c.Send(data)
+	}
+}
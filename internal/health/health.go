@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// heartbeatGrace is how late a heartbeat may be before its worker counts as
+// stuck
+const heartbeatGrace = time.Minute
+
+// Check reports whether a dependency is usable
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of one check
+type CheckResult struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Report is the outcome of every check; its status is failing when any
+// check failed or the server is draining
+type Report struct {
+	Status   string                 `json:"status"`
+	Draining bool                   `json:"draining,omitempty"`
+	Checks   map[string]CheckResult `json:"checks"`
+}
+
+// namedCheck is a registered check
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks deciding whether the server is ready for traffic
+type Checker struct {
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewChecker creates a checker without checks
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add registers a check under a name
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes every later run fail, so load balancers stop routing new
+// requests to a server that is shutting down
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Run runs every check concurrently. Checks still running when the context
+// ends fail with its error. Once draining, no checks run and the report fails.
+func (c *Checker) Run(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusFailing, Draining: true, Checks: map[string]CheckResult{}}
+	}
+
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check.check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	for i, check := range checks {
+		report.Checks[check.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	return report
+}
+
+// runCheck runs a check, giving up when the context ends
+func runCheck(ctx context.Context, check Check) CheckResult {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Heartbeat tracks that a background worker keeps running
+type Heartbeat struct {
+	deadline atomic.Int64
+}
+
+// Beat records that the worker is alive and will beat again within the
+// interval
+func (h *Heartbeat) Beat(interval time.Duration) {
+	h.deadline.Store(time.Now().Add(interval + heartbeatGrace).UnixNano())
+}
+
+// Check fails until the first beat and when a beat is overdue
+func (h *Heartbeat) Check(context.Context) error {
+	deadline := h.deadline.Load()
+	if deadline == 0 {
+		return errors.New("worker has not started")
+	}
+	if late := time.Since(time.Unix(0, deadline)); late > 0 {
+		return fmt.Errorf("worker heartbeat is %v overdue", late.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Package images turns uploaded pictures into square JPEG thumbnails.
+// Thumbnails are encoded from decoded pixels only, so EXIF and any other
+// metadata of the upload, such as GPS coordinates, are never carried over.
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"slices"
+
+	"golang.org/x/image/draw"
+
+	// Decoders of the other accepted formats
+	_ "golang.org/x/image/webp"
+	_ "image/gif"
+	_ "image/png"
+)
+
+// ContentType is the content type of every thumbnail
+const ContentType = "image/jpeg"
+
+// jpegQuality is the quality thumbnails are encoded with
+const jpegQuality = 85
+
+// Formats are the accepted upload formats, as named by image.DecodeConfig
+var Formats = []string{"jpeg", "png", "gif", "webp"}
+
+// ErrUnsupportedFormat is returned for uploads that are not in one of Formats
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ErrTooManyPixels is returned for images larger than the pixel limit,
+// before they are decoded
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// ErrCorrupt is returned for uploads that cannot be decoded
+var ErrCorrupt = errors.New("corrupt image")
+
+// Thumbnails decodes an image of at most maxPixels pixels, crops it to a
+// centred square and returns a JPEG of each size, keyed by size. The EXIF
+// orientation of JPEG uploads is applied, so photos taken on their side come
+// out upright. Transparent areas become white.
+func Thumbnails(data []byte, sizes []int, maxPixels int) (map[int][]byte, error) {
+	// Check the format and dimensions before decoding allocates the pixels
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if !slices.Contains(Formats, format) {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrCorrupt)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > int64(maxPixels) {
+		return nil, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	square := centredSquare(src.Bounds())
+	thumbnails := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, orient(dst, orientation), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// centredSquare returns the largest square centred in r
+func centredSquare(r image.Rectangle) image.Rectangle {
+	side := min(r.Dx(), r.Dy())
+	x := r.Min.X + (r.Dx()-side)/2
+	y := r.Min.Y + (r.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// businessRefreshInterval is how long business gauges are served from the
+// last snapshot before the database is queried again
+const businessRefreshInterval = 30 * time.Second
+
+// businessQueryTimeout bounds the queries of one snapshot
+const businessQueryTimeout = 10 * time.Second
+
+// Business gauges, labelled by tenant slug
+var (
+	activeEmployeesDesc = prometheus.NewDesc(namespace+"_active_employees",
+		"Employees with active status.", []string{"tenant"}, nil)
+	openAssessmentsDesc = prometheus.NewDesc(namespace+"_open_assessments",
+		"Assessments neither completed nor cancelled, by status.", []string{"tenant", "status"}, nil)
+	overdueGoalsDesc = prometheus.NewDesc(namespace+"_overdue_goals",
+		"Active goals past their due date.", []string{"tenant"}, nil)
+)
+
+// tenantSnapshot holds the business figures of one tenant
+type tenantSnapshot struct {
+	tenant          string
+	activeEmployees int64
+	openAssessments map[string]int64
+	overdueGoals    int64
+}
+
+// businessCollector queries the business gauges of every tenant, at most
+// once per refresh interval
+type businessCollector struct {
+	repos repository.RepositoryFactory
+
+	mu        sync.Mutex
+	snapshot  []tenantSnapshot
+	refreshed time.Time
+}
+
+// RegisterBusiness exposes headcount, assessment and goal gauges per tenant
+func RegisterBusiness(repos repository.RepositoryFactory) {
+	Registry.MustRegister(&businessCollector{repos: repos})
+}
+
+// Describe implements prometheus.Collector
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeEmployeesDesc
+	ch <- openAssessmentsDesc
+	ch <- overdueGoalsDesc
+}
+
+// Collect implements prometheus.Collector. When the database cannot be
+// queried the previous snapshot is served.
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	if time.Since(c.refreshed) >= businessRefreshInterval {
+		ctx, cancel := context.WithTimeout(context.Background(), businessQueryTimeout)
+		snapshot, err := c.query(ctx)
+		cancel()
+		if err != nil {
+			slog.Error("Failed to query business metrics", "error", err)
+		} else {
+			c.snapshot = snapshot
+		}
+		c.refreshed = time.Now()
+	}
+	snapshot := c.snapshot
+	c.mu.Unlock()
+
+	for _, s := range snapshot {
+		ch <- prometheus.MustNewConstMetric(activeEmployeesDesc, prometheus.GaugeValue, float64(s.activeEmployees), s.tenant)
+		for status, count := range s.openAssessments {
+			ch <- prometheus.MustNewConstMetric(openAssessmentsDesc, prometheus.GaugeValue, float64(count), s.tenant, status)
+		}
+		ch <- prometheus.MustNewConstMetric(overdueGoalsDesc, prometheus.GaugeValue, float64(s.overdueGoals), s.tenant)
+	}
+}
+
+// query reads the business figures of every tenant as the system actor
+func (c *businessCollector) query(ctx context.Context) ([]tenantSnapshot, error) {
+	tenants, err := c.repos.Tenants().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC()
+	snapshot := make([]tenantSnapshot, 0, len(tenants))
+	for _, tenant := range tenants {
+		tenantCtx := repository.ContextWithActor(repository.ContextWithTenant(ctx, tenant.ID), repository.SystemActor)
+		s := tenantSnapshot{tenant: tenant.Slug}
+		if s.activeEmployees, err = c.repos.Employees().CountActive(tenantCtx); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.Slug, err)
+		}
+		if s.openAssessments, err = c.repos.Assessments().CountOpenByStatus(tenantCtx); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.Slug, err)
+		}
+		if s.overdueGoals, err = c.repos.Goals().CountOverdue(tenantCtx, today); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.Slug, err)
+		}
+		snapshot = append(snapshot, s)
+	}
+	return snapshot, nil
+}
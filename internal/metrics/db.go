@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Connection pool and query metrics, labelled by pool ("primary" or "replica")
+var (
+	poolAcquiredDesc = poolDesc("acquired_connections", "Connections currently in use.")
+	poolIdleDesc     = poolDesc("idle_connections", "Connections currently idle.")
+	poolTotalDesc    = poolDesc("total_connections", "Connections currently open.")
+	poolMaxDesc      = poolDesc("max_connections", "Maximum size of the pool.")
+	poolAcquiresDesc = poolDesc("acquires_total", "Successful connection acquires.")
+	poolCanceledDesc = poolDesc("canceled_acquires_total", "Acquires canceled by their context.")
+	poolWaitsDesc    = poolDesc("waits_total", "Acquires that waited for a free connection.")
+	poolWaitTimeDesc = poolDesc("wait_seconds_total", "Time spent waiting for a free connection.")
+	poolNewConnsDesc = poolDesc("new_connections_total", "Connections opened.")
+
+	replicaHealthyDesc = prometheus.NewDesc(namespace+"_db_replica_healthy",
+		"Whether reads are routed to the read replica.", nil, nil)
+	replicaLagDesc = prometheus.NewDesc(namespace+"_db_replica_lag_seconds",
+		"Replication lag measured by the last check.", nil, nil)
+
+	queriesDesc = prometheus.NewDesc(namespace+"_db_queries_total",
+		"Statements by repository method.", []string{"method"}, nil)
+	queryErrorsDesc = prometheus.NewDesc(namespace+"_db_query_errors_total",
+		"Failed statements by repository method.", []string{"method"}, nil)
+	slowQueriesDesc = prometheus.NewDesc(namespace+"_db_slow_queries_total",
+		"Statements slower than the slow-query threshold by repository method.", []string{"method"}, nil)
+	queryTimeDesc = prometheus.NewDesc(namespace+"_db_query_seconds_total",
+		"Time spent in statements by repository method.", []string{"method"}, nil)
+)
+
+// poolDesc describes a connection pool metric
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(namespace+"_db_pool_"+name, help, []string{"pool"}, nil)
+}
+
+// dbCollector reads the pool and query statistics at scrape time
+type dbCollector struct {
+	db *repository.DBPool
+}
+
+// RegisterDB exposes the statistics of the database pools and their queries
+func RegisterDB(db *repository.DBPool) {
+	Registry.MustRegister(&dbCollector{db: db})
+}
+
+// Describe implements prometheus.Collector
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		poolAcquiredDesc, poolIdleDesc, poolTotalDesc, poolMaxDesc, poolAcquiresDesc,
+		poolCanceledDesc, poolWaitsDesc, poolWaitTimeDesc, poolNewConnsDesc,
+		replicaHealthyDesc, replicaLagDesc,
+		queriesDesc, queryErrorsDesc, slowQueriesDesc, queryTimeDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector. The pool counters come straight
+// from pgxpool and the query counters from the tracer's totals, so resetting
+// /metrics/db affects neither and they never go backwards.
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	collectPool(ch, "primary", c.db.Stats())
+
+	if replica := c.db.Replica; replica != nil {
+		collectPool(ch, "replica", replica.Pool.Stat())
+		healthy := 0.0
+		if replica.Healthy() {
+			healthy = 1
+		}
+		ch <- prometheus.MustNewConstMetric(replicaHealthyDesc, prometheus.GaugeValue, healthy)
+		ch <- prometheus.MustNewConstMetric(replicaLagDesc, prometheus.GaugeValue, replica.Lag().Seconds())
+	}
+
+	if c.db.Tracer != nil {
+		for _, stat := range c.db.Tracer.Totals() {
+			ch <- prometheus.MustNewConstMetric(queriesDesc, prometheus.CounterValue, float64(stat.Count), stat.Method)
+			ch <- prometheus.MustNewConstMetric(queryErrorsDesc, prometheus.CounterValue, float64(stat.Errors), stat.Method)
+			ch <- prometheus.MustNewConstMetric(slowQueriesDesc, prometheus.CounterValue, float64(stat.Slow), stat.Method)
+			ch <- prometheus.MustNewConstMetric(queryTimeDesc, prometheus.CounterValue, stat.TotalTime.Seconds(), stat.Method)
+		}
+	}
+}
+
+// collectPool sends the metrics of one connection pool
+func collectPool(ch chan<- prometheus.Metric, pool string, s *pgxpool.Stat) {
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, pool)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, pool)
+	}
+
+	gauge(poolAcquiredDesc, float64(s.AcquiredConns()))
+	gauge(poolIdleDesc, float64(s.IdleConns()))
+	gauge(poolTotalDesc, float64(s.TotalConns()))
+	gauge(poolMaxDesc, float64(s.MaxConns()))
+	counter(poolAcquiresDesc, float64(s.AcquireCount()))
+	counter(poolCanceledDesc, float64(s.CanceledAcquireCount()))
+	counter(poolWaitsDesc, float64(s.EmptyAcquireCount()))
+	counter(poolWaitTimeDesc, s.EmptyAcquireWaitTime().Seconds())
+	counter(poolNewConnsDesc, float64(s.NewConnsCount()))
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"html"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests that matched no route, so that scanners
+// probing random paths cannot create unbounded label values
+const unmatchedRoute = "unmatched"
+
+// unmatchedLocalsKey is the Fiber locals key flagging a request NotFound answered
+const unmatchedLocalsKey = "metrics.unmatched"
+
+// HTTP request metrics, labelled by the route pattern rather than the path
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Middleware records the count and latency of every request. Errors are
+// passed to the application's error handler here, so the recorded status is
+// the one the client receives.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := c.Route().Path
+		if unmatched, _ := c.Locals(unmatchedLocalsKey).(bool); unmatched {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Response().StatusCode())
+
+		httpRequests.WithLabelValues(c.Method(), route, status).Inc()
+		httpDuration.WithLabelValues(c.Method(), route, status).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+// NotFound answers requests that matched no route, like Fiber does. Register
+// it after every other route, so that such requests are labelled "unmatched"
+// rather than with the path of the last middleware they passed through.
+func NotFound() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(unmatchedLocalsKey, true)
+		return fiber.NewError(fiber.StatusNotFound, "Cannot "+c.Method()+" "+html.EscapeString(c.Path()))
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every application metric
+const namespace = "byebob"
+
+// Registry holds the metrics exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+// Background job metrics
+var (
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Background job runs by job and outcome.",
+	}, []string{"job", "outcome"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of background job runs.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 60, 300, 900},
+	}, []string{"job"})
+
+	jobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful run of a background job.",
+	}, []string{"job"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		jobRuns,
+		jobDuration,
+		jobLastSuccess,
+	)
+}
+
+// Handler returns a handler serving the registered metrics in the Prometheus
+// text format
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}))
+}
+
+// ObserveJob records the outcome and duration of a background job run
+func ObserveJob(job string, elapsed time.Duration, err error) {
+	jobDuration.WithLabelValues(job).Observe(elapsed.Seconds())
+	if err != nil {
+		jobRuns.WithLabelValues(job, "failure").Inc()
+		return
+	}
+	jobRuns.WithLabelValues(job, "success").Inc()
+	jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+}
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Identity headers set by the authenticating proxy in front of the server.
+// They are only trusted on requests coming straight from the proxy.
+const (
+	UserIDHeader    = "X-User-ID"
+	UserRolesHeader = "X-User-Roles"
+)
+
+// hrRole is the role that may see and manage every employee of a tenant
+const hrRole = "hr"
+
+// Fiber locals keys holding the acting user, how they were identified and
+// the tenant their identity belongs to
+const (
+	actorLocalsKey       = "actor"
+	tokenLocalsKey       = "actor_token"
+	actorTenantLocalsKey = "actor_tenant"
+)
+
+// Authenticator identifies the acting user of each request, from a Clerk
+// session token or from the identity headers of a trusted proxy
+type Authenticator struct {
+	clerkKey          *rsa.PublicKey
+	identityProxies   []netip.Prefix
+	trustTenantHeader bool
+}
+
+// identity is the acting user of a request and the tenant slug their
+// identity names, if any
+type identity struct {
+	actor   repository.Actor
+	tenant  string
+	byToken bool
+}
+
+// NewAuthenticator creates an authenticator. clerkPubKey is the PEM public
+// key Clerk signs session tokens with; without one, bearer tokens are
+// rejected. Identity headers are accepted only from the IP addresses or CIDR
+// ranges in identityProxies, and ignored from anyone else.
+func NewAuthenticator(clerkPubKey string, identityProxies []string) (*Authenticator, error) {
+	a := &Authenticator{}
+	if clerkPubKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(clerkPubKey))
+		if err != nil {
+			return nil, fmt.Errorf("clerk_pub_key must be the PEM public key Clerk signs session tokens with: %w", err)
+		}
+		a.clerkKey = key
+	}
+	for _, proxy := range identityProxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(proxy)
+			if aerr != nil {
+				return nil, fmt.Errorf("identity proxy %q is not an IP address or CIDR range", proxy)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		a.identityProxies = append(a.identityProxies, prefix.Masked())
+	}
+	return a, nil
+}
+
+// TrustTenantHeader lets any client name its tenant with the X-Tenant
+// header, rather than only a trusted identity proxy. For development only.
+func (a *Authenticator) TrustTenantHeader(trust bool) {
+	a.trustTenantHeader = trust
+}
+
+// sessionClaims are the claims of a Clerk session token. The session token
+// template adds the employee ID, roles and tenant slug, kept in the user's
+// public metadata.
+type sessionClaims struct {
+	EmployeeID string   `json:"employee_id"`
+	Roles      roleList `json:"roles"`
+	Tenant     string   `json:"tenant"`
+	jwt.RegisteredClaims
+}
+
+// roleList holds roles given as a JSON array or a comma-separated string
+type roleList []string
+
+// UnmarshalJSON accepts an array of roles or a comma-separated string
+func (r *roleList) UnmarshalJSON(data []byte) error {
+	var roles []string
+	if err := json.Unmarshal(data, &roles); err == nil {
+		*r = roles
+		return nil
+	}
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return fmt.Errorf("roles must be an array or a comma-separated string")
+	}
+	*r = strings.Split(joined, ",")
+	return nil
+}
+
+// Actor identifies the acting employee and scopes the request context to
+// them, so row-level security limits every repository call to the employee,
+// their reporting subtree, or everyone for HR. A bearer token must be a
+// valid Clerk session token; identity headers from anyone but a trusted
+// proxy are stripped, and so is the X-Tenant header unless the tenant header
+// is trusted. Requests without an identity see no employee data.
+func (a *Authenticator) Actor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := a.identify(c)
+		if err != nil {
+			return err
+		}
+
+		c.Locals(actorLocalsKey, id.actor)
+		c.Locals(tokenLocalsKey, id.byToken)
+		c.Locals(actorTenantLocalsKey, id.tenant)
+		c.SetUserContext(repository.ContextWithActor(c.UserContext(), id.actor))
+		return c.Next()
+	}
+}
+
+// identify returns the acting user, the tenant their identity names and
+// whether they presented a verified bearer token
+func (a *Authenticator) identify(c *fiber.Ctx) (identity, error) {
+	if header := c.Get(fiber.HeaderAuthorization); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || a.clerkKey == nil {
+			return identity{}, fiber.NewError(fiber.StatusUnauthorized, "only Clerk bearer tokens are accepted")
+		}
+		return a.verify(token)
+	}
+
+	if !a.fromIdentityProxy(c) {
+		c.Request().Header.Del(UserIDHeader)
+		c.Request().Header.Del(UserRolesHeader)
+		if !a.trustTenantHeader {
+			c.Request().Header.Del(TenantHeader)
+		}
+		return identity{tenant: c.Get(TenantHeader)}, nil
+	}
+	actor, err := newActor(c.Get(UserIDHeader), strings.Split(c.Get(UserRolesHeader), ","))
+	return identity{actor: actor, tenant: c.Get(TenantHeader)}, err
+}
+
+// verify checks a Clerk session token and returns the identity it names
+func (a *Authenticator) verify(token string) (identity, error) {
+	var claims sessionClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.clerkKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return identity{}, fiber.NewError(fiber.StatusUnauthorized, "invalid bearer token: "+err.Error())
+	}
+	if claims.EmployeeID == "" {
+		return identity{}, fiber.NewError(fiber.StatusUnauthorized, "bearer token has no employee_id claim")
+	}
+	actor, err := newActor(claims.EmployeeID, claims.Roles)
+	return identity{actor: actor, tenant: claims.Tenant, byToken: true}, err
+}
+
+// fromIdentityProxy reports whether the request comes straight from a
+// trusted identity proxy. The peer address is used rather than any
+// forwarded-for header, which clients control.
+func (a *Authenticator) fromIdentityProxy(c *fiber.Ctx) bool {
+	addr, ok := netip.AddrFromSlice(c.Context().RemoteIP())
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range a.identityProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// newActor builds the acting user from an employee ID and roles
+func newActor(id string, roles []string) (repository.Actor, error) {
+	var actor repository.Actor
+	if id = strings.TrimSpace(id); id != "" {
+		if _, err := uuid.Parse(id); err != nil {
+			return actor, fiber.NewError(fiber.StatusUnauthorized, "the acting user must be an employee ID")
+		}
+		actor.EmployeeID = id
+	}
+	for _, role := range roles {
+		if strings.EqualFold(strings.TrimSpace(role), hrRole) {
+			actor.HR = true
+		}
+	}
+	return actor, nil
+}
+
+// RequireHR rejects requests whose acting user is not in HR
+func RequireHR() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !CurrentActor(c).HR {
+			return fiber.NewError(fiber.StatusForbidden, "this action is restricted to HR")
+		}
+		return c.Next()
+	}
+}
+
+// CurrentActor returns the acting user of the request
+func CurrentActor(c *fiber.Ctx) repository.Actor {
+	actor, _ := c.Locals(actorLocalsKey).(repository.Actor)
+	return actor
+}
+
+// ActorTenant returns the tenant slug named by the acting user's token or
+// trusted proxy, or by the X-Tenant header where that is trusted
+func ActorTenant(c *fiber.Ctx) string {
+	tenant, _ := c.Locals(actorTenantLocalsKey).(string)
+	return tenant
+}
+
+// TokenAuthenticated reports whether the acting user presented a verified
+// bearer token rather than browser credentials
+func TokenAuthenticated(c *fiber.Ctx) bool {
+	byToken, _ := c.Locals(tokenLocalsKey).(bool)
+	return byToken
+}
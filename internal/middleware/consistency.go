@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReadYourWrites pins the reads of a request to the primary database once
+// the request has written, so it never reads older data from the replica
+// than it has just stored
+func ReadYourWrites() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(repository.ContextWithReadYourWrites(c.UserContext()))
+		return c.Next()
+	}
+}
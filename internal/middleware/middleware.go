@@ -1,18 +1,50 @@
 package middleware
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 )
 
-// RequestLogger middleware for logging requests
+// RequestLogger logs one line per request with its route, status, latency,
+// acting user and tenant; the request ID comes from the request context.
+// Errors are passed to the application's error handler here, so the logged
+// status is the one the client receives.
 func RequestLogger() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Continue to the next middleware/handler
-		err := c.Next()
+		start := time.Now()
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
 
-		// Return any errors that occurred during the request
-		return err
+		status := c.Response().StatusCode()
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", c.Route().Path),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		}
+		if actor := CurrentActor(c); actor.EmployeeID != "" {
+			attrs = append(attrs, slog.String("user_id", actor.EmployeeID))
+		}
+		if tenant := CurrentTenant(c); tenant != nil {
+			attrs = append(attrs, slog.String("tenant", tenant.Slug))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
 	}
 }
-
-// Other middleware functions will be added here as needed 
\ No newline at end of file
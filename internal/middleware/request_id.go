@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/gfurduy/byebob/internal/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID correlating a request across services
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// RequestID takes the request ID from the X-Request-ID header, or generates
+// one, echoes it in the response and carries it in the request context so
+// every log line of the request includes it
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(RequestIDHeader, id)
+		c.SetUserContext(logging.ContextWithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to
+// log and echo: non-empty, bounded and made of printable ASCII
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
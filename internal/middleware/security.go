@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/csrf"
+	"github.com/gofiber/fiber/v2/middleware/helmet"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// contentSecurityPolicy allows only the application's own scripts, styles
+// and connections
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self'; " +
+	"style-src 'self'; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+// hstsMaxAge is how long browsers keep to HTTPS once they have seen it, in
+// seconds
+const hstsMaxAge = 365 * 24 * 60 * 60
+
+// SecurityHeaders sets the content security policy, HSTS on HTTPS requests,
+// and headers forbidding framing and MIME sniffing
+func SecurityHeaders() fiber.Handler {
+	return helmet.New(helmet.Config{
+		ContentSecurityPolicy: contentSecurityPolicy,
+		HSTSMaxAge:            hstsMaxAge,
+		XFrameOptions:         "DENY",
+		// The CSRF check requires a same-origin Referer on HTTPS
+		ReferrerPolicy: "same-origin",
+	})
+}
+
+// CSRFHeader carries the CSRF token on unsafe requests; the layout makes
+// htmx send it with every request
+const CSRFHeader = "X-CSRF-Token"
+
+// csrfCookie holds the token the CSRF header must match
+const csrfCookie = "csrf_"
+
+// csrfLocalsKey is the Fiber locals key holding the request's CSRF token
+const csrfLocalsKey = "csrf"
+
+// CSRF rejects unsafe requests whose X-CSRF-Token header does not match
+// their CSRF cookie and a token issued by the server, and issues a token on
+// safe requests. Requests authenticated by a verified bearer token come from
+// API clients rather than browsers and are not checked; Actor must run first.
+// A nil storage keeps tokens in memory.
+func CSRF(storage fiber.Storage, secureCookie bool) fiber.Handler {
+	return csrf.New(csrf.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return TokenAuthenticated(c)
+		},
+		KeyLookup:         "header:" + CSRFHeader,
+		CookieName:        csrfCookie,
+		CookieSecure:      secureCookie,
+		CookieHTTPOnly:    true,
+		CookieSameSite:    "Lax",
+		CookieSessionOnly: true,
+		Expiration:        time.Hour,
+		Storage:           storage,
+		ContextKey:        csrfLocalsKey,
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return fiber.NewError(fiber.StatusForbidden, "CSRF check failed: "+err.Error())
+		},
+	})
+}
+
+// CSRFToken returns the CSRF token issued for the request, to render into
+// pages
+func CSRFToken(c *fiber.Ctx) string {
+	token, _ := c.Locals(csrfLocalsKey).(string)
+	return token
+}
+
+// rateLimitWindow is the window the rate limits count requests over
+const rateLimitWindow = time.Minute
+
+// RateLimitByIP allows each client IP at most max requests per minute. A nil
+// storage counts in memory, per server instance.
+func RateLimitByIP(storage fiber.Storage, max int) fiber.Handler {
+	return rateLimit(storage, max, func(c *fiber.Ctx) string {
+		return "ip:" + c.IP()
+	})
+}
+
+// RateLimitByUser allows each acting user, as identified by Actor, at most
+// max requests per minute. Anonymous requests count against their client IP
+// instead.
+func RateLimitByUser(storage fiber.Storage, max int) fiber.Handler {
+	return rateLimit(storage, max, func(c *fiber.Ctx) string {
+		if id := CurrentActor(c).EmployeeID; id != "" {
+			return "user:" + id
+		}
+		return "anon:" + c.IP()
+	})
+}
+
+// rateLimit limits requests per key, skipping requests without one; a max of
+// 0 disables it
+func rateLimit(storage fiber.Storage, max int, key func(*fiber.Ctx) string) fiber.Handler {
+	if max <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+	return limiter.New(limiter.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return key(c) == ""
+		},
+		Max:          max,
+		Expiration:   rateLimitWindow,
+		KeyGenerator: key,
+		Storage:      storage,
+		LimitReached: func(c *fiber.Ctx) error {
+			return fiber.ErrTooManyRequests
+		},
+	})
+}
+
+// BodyLimit rejects request bodies larger than limit bytes with 413, except
+// for requests allowed more by skip. The server's own body limit must be
+// high enough for those; this one applies to everything else.
+func BodyLimit(limit int, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(c.Request().Body()) > limit && (skip == nil || !skip(c)) {
+			return fiber.ErrRequestEntityTooLarge
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TenantHeader names the tenant by slug when the hostname does not identify
+// it. It is only read from trusted identity proxies, or from anyone when the
+// Authenticator trusts the tenant header, as in development.
+const TenantHeader = "X-Tenant"
+
+// tenantLocalsKey is the Fiber locals key holding the resolved tenant
+const tenantLocalsKey = "tenant"
+
+// Tenant resolves the tenant of each request from its hostname or the tenant
+// named by the acting user's identity, and scopes the request context to it,
+// so every repository call runs under that tenant's row-level security
+// policies. Acting users are only let into their own tenant. Actor must run
+// first.
+func Tenant(tenants *services.TenantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenant, err := tenants.Resolve(c.UserContext(), c.Hostname(), ActorTenant(c))
+		if err != nil {
+			return err
+		}
+		if CurrentActor(c) != (repository.Actor{}) {
+			if err := tenants.CheckMember(tenant, ActorTenant(c)); err != nil {
+				return err
+			}
+		}
+
+		c.Locals(tenantLocalsKey, tenant)
+		c.SetUserContext(repository.ContextWithTenant(c.UserContext(), tenant.ID))
+		return c.Next()
+	}
+}
+
+// CurrentTenant returns the tenant resolved for the request
+func CurrentTenant(c *fiber.Ctx) *repository.Tenant {
+	tenant, _ := c.Locals(tenantLocalsKey).(*repository.Tenant)
+	return tenant
+}
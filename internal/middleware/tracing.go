@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans started by the HTTP middleware
+const tracerName = "github.com/gfurduy/byebob/internal/middleware"
+
+// Span attributes identifying the tenant of a request
+const (
+	tenantIDAttribute   = attribute.Key("tenant.id")
+	tenantSlugAttribute = attribute.Key("tenant.slug")
+)
+
+// Tracing starts a server span for each request, continuing the trace named
+// by an incoming traceparent header, and carries it in the request context
+// so the service and repository spans nest beneath it. Errors are passed to
+// the application's error handler here, so the span records the status the
+// client receives.
+func Tracing() fiber.Handler {
+	tracer := otel.Tracer(tracerName)
+
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := tracer.Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		if err := c.Next(); err != nil {
+			span.RecordError(err)
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRoute(route),
+			semconv.HTTPResponseStatusCode(status),
+		)
+		if actor := CurrentActor(c); actor.EmployeeID != "" {
+			span.SetAttributes(semconv.EnduserID(actor.EmployeeID))
+		}
+		if tenant := CurrentTenant(c); tenant != nil {
+			span.SetAttributes(tenantIDAttribute.String(tenant.ID), tenantSlugAttribute.String(tenant.Slug))
+		}
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return nil
+	}
+}
+
+// headerCarrier adapts the request headers to the propagation API
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+// Get returns the value of a request header
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+// Set sets a request header
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+// Keys lists the request header names
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
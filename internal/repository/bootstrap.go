@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Group roles that hold the privileges granted by the migrations
+const (
+	AppRole      = "byebob_app_role"
+	AdminRole    = "byebob_admin_role"
+	ReadonlyRole = "byebob_readonly_role"
+)
+
+// DBUser is a login user provisioned by BootstrapRoles
+type DBUser struct {
+	Name     string
+	Password string
+	Role     string
+}
+
+// BootstrapRoles creates the group roles and login users if they are missing,
+// sets the users' passwords and grants each its group role. It is safe to run
+// repeatedly, and rotates passwords when they change. Users without a
+// password are skipped.
+func BootstrapRoles(ctx context.Context, pool *pgxpool.Pool, users []DBUser) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, role := range []string{AppRole, AdminRole, ReadonlyRole} {
+		created, err := ensureRole(ctx, tx, role, "NOLOGIN")
+		if err != nil {
+			return err
+		}
+		if created {
+			slog.Info("Created role", "role", role)
+		}
+	}
+
+	for _, user := range users {
+		if user.Password == "" {
+			slog.Warn("Skipping user: no password configured", "user", user.Name)
+			continue
+		}
+
+		options := "LOGIN PASSWORD " + quoteLiteral(user.Password)
+		created, err := ensureRole(ctx, tx, user.Name, options)
+		if err != nil {
+			return err
+		}
+		if !created {
+			if _, err := tx.Exec(ctx, "ALTER ROLE "+pgx.Identifier{user.Name}.Sanitize()+" WITH "+options); err != nil {
+				return fmt.Errorf("failed to update user %s: %w", user.Name, err)
+			}
+		}
+
+		grant := fmt.Sprintf("GRANT %s TO %s", pgx.Identifier{user.Role}.Sanitize(), pgx.Identifier{user.Name}.Sanitize())
+		if _, err := tx.Exec(ctx, grant); err != nil {
+			return fmt.Errorf("failed to grant %s to %s: %w", user.Role, user.Name, err)
+		}
+
+		if created {
+			slog.Info("Created user", "user", user.Name, "role", user.Role)
+		} else {
+			slog.Info("Updated user", "user", user.Name, "role", user.Role)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit roles: %w", err)
+	}
+	return nil
+}
+
+// ensureRole creates the role with the given options unless it exists, and
+// reports whether it did
+func ensureRole(ctx context.Context, tx pgx.Tx, name, options string) (bool, error) {
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up role %s: %w", name, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE ROLE "+pgx.Identifier{name}.Sanitize()+" WITH "+options); err != nil {
+		return false, fmt.Errorf("failed to create role %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// quoteLiteral quotes a string for use as an SQL literal. Role statements do
+// not accept bind parameters.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -3,7 +3,8 @@ package repository
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
@@ -22,6 +23,35 @@ var (
 type DBPool struct {
 	Pool *pgxpool.Pool
 	Cfg  *config.Config
+
+	// Replica is the optional read replica, nil when none is configured
+	Replica *ReplicaPool
+
+	// Tracer times the queries of both pools
+	Tracer *QueryTracer
+
+	statsMu      sync.Mutex
+	baseline     PoolStats
+	replicaBase  PoolStats
+	statsResetAt time.Time
+}
+
+// PoolStats is a snapshot of a connection pool. The gauges reflect the
+// moment of the snapshot; the counters and durations accumulate since the
+// statistics were last reset.
+type PoolStats struct {
+	AcquiredConns     int32         `json:"acquired_conns"`
+	IdleConns         int32         `json:"idle_conns"`
+	TotalConns        int32         `json:"total_conns"`
+	MaxConns          int32         `json:"max_conns"`
+	AcquireCount      int64         `json:"acquire_count"`
+	CanceledAcquire   int64         `json:"canceled_acquire_count"`
+	WaitCount         int64         `json:"wait_count"` // acquires that waited for a free connection
+	WaitDuration      time.Duration `json:"wait_duration_ns"`
+	AcquireDuration   time.Duration `json:"acquire_duration_ns"`
+	NewConns          int64         `json:"new_conns"`
+	LifetimeDestroyed int64         `json:"max_lifetime_destroy_count"`
+	IdleDestroyed     int64         `json:"max_idle_destroy_count"`
 }
 
 // PoolConfig defines configuration for the database connection pool
@@ -50,19 +80,56 @@ func DefaultPoolConfig() *PoolConfig {
 	}
 }
 
-// NewDBPool creates a new database connection pool with default settings
+// NewDBPool creates a new database connection pool with default settings,
+// connected as the least-privileged application user
 func NewDBPool(cfg *config.Config) (*DBPool, error) {
 	return NewDBPoolWithConfig(cfg, DefaultPoolConfig())
 }
 
-// NewDBPoolWithConfig creates a new database connection pool with custom configuration
+// NewAdminDBPool creates a small connection pool with the migration
+// credentials (DB_USER or RAILWAY_DB_URL), for schema changes and bootstrapping
+func NewAdminDBPool(cfg *config.Config) (*DBPool, error) {
+	poolCfg := DefaultPoolConfig()
+	poolCfg.MaxConns = 2
+	poolCfg.MinConns = 0
+	return newDBPool(cfg, cfg.PostgresConnectionString(), poolCfg, newQueryTracer(cfg))
+}
+
+// newQueryTracer creates a query tracer with the configured slow-query threshold
+func newQueryTracer(cfg *config.Config) *QueryTracer {
+	return NewQueryTracer(time.Duration(cfg.SlowQueryThresholdMs) * time.Millisecond)
+}
+
+// NewDBPoolWithConfig creates a new database connection pool with custom
+// configuration, connected as the least-privileged application user, and a
+// pool for the read replica when one is configured
 func NewDBPoolWithConfig(cfg *config.Config, poolCfg *PoolConfig) (*DBPool, error) {
+	db, err := newDBPool(cfg, cfg.AppConnectionString(), poolCfg, newQueryTracer(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ReplicaDBURL != "" {
+		slog.Info("Connecting to read replica")
+		replica, err := newDBPool(cfg, cfg.AppReplicaConnectionString(), poolCfg, db.Tracer)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("read replica: %w", err)
+		}
+		db.Replica = NewReplicaPool(replica.Pool, time.Duration(cfg.ReplicaMaxLagSeconds)*time.Second)
+	}
+	return db, nil
+}
+
+// newDBPool connects a pool to the given connection string, timing its
+// queries with the tracer
+func newDBPool(cfg *config.Config, connString string, poolCfg *PoolConfig, tracer *QueryTracer) (*DBPool, error) {
 	var pool *pgxpool.Pool
 	var connectConfig *pgxpool.Config
 	var err error
 
 	// Parse the connection string
-	connectConfig, err = pgxpool.ParseConfig(cfg.PostgresConnectionString())
+	connectConfig, err = pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse connection string: %w", err)
 	}
@@ -73,6 +140,9 @@ func NewDBPoolWithConfig(cfg *config.Config, poolCfg *PoolConfig) (*DBPool, erro
 	connectConfig.MaxConnLifetime = poolCfg.MaxConnLifetime
 	connectConfig.MaxConnIdleTime = poolCfg.MaxConnIdleTime
 	connectConfig.HealthCheckPeriod = poolCfg.HealthCheckPeriod
+	connectConfig.ConnConfig.Tracer = tracer
+	// Directory search relies on the trigram threshold matching its own
+	connectConfig.ConnConfig.RuntimeParams["pg_trgm.word_similarity_threshold"] = strconv.FormatFloat(searchSimilarityThreshold, 'f', -1, 64)
 
 	// Attempt to connect with retries
 	retryDelay := poolCfg.RetryDelay
@@ -94,7 +164,7 @@ func NewDBPoolWithConfig(cfg *config.Config, poolCfg *PoolConfig) (*DBPool, erro
 		
 		if err == nil {
 			// Connection successful
-			log.Printf("Successfully connected to PostgreSQL database (attempt %d/%d)", i+1, poolCfg.MaxRetries)
+			slog.Info("Successfully connected to PostgreSQL database", "attempt", i+1, "max_attempts", poolCfg.MaxRetries)
 			break
 		}
 		
@@ -109,8 +179,7 @@ func NewDBPoolWithConfig(cfg *config.Config, poolCfg *PoolConfig) (*DBPool, erro
 		
 		// If this is not the last attempt, wait and retry
 		if i < poolCfg.MaxRetries-1 {
-			log.Printf("Failed to connect to database (attempt %d/%d): %v", i+1, poolCfg.MaxRetries, err)
-			log.Printf("Retrying in %v...", retryDelay)
+			slog.Warn("Failed to connect to database", "attempt", i+1, "max_attempts", poolCfg.MaxRetries, "error", err, "retry_in", retryDelay)
 			time.Sleep(retryDelay)
 			
 			// Exponential backoff: increase delay for next retry
@@ -124,16 +193,22 @@ func NewDBPoolWithConfig(cfg *config.Config, poolCfg *PoolConfig) (*DBPool, erro
 	}
 
 	return &DBPool{
-		Pool: pool,
-		Cfg:  cfg,
+		Pool:         pool,
+		Cfg:          cfg,
+		Tracer:       tracer,
+		statsResetAt: time.Now(),
 	}, nil
 }
 
-// Close closes the database connection pool
+// Close closes the database connection pool and the replica pool, if any
 func (db *DBPool) Close() {
+	if db.Replica != nil {
+		db.Replica.Pool.Close()
+		slog.Info("Read replica connection pool closed")
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
-		log.Println("Database connection pool closed")
+		slog.Info("Database connection pool closed")
 	}
 }
 
@@ -196,10 +271,66 @@ func (db *DBPool) Stats() *pgxpool.Stat {
 	return db.Pool.Stat()
 }
 
-// ResetStats resets the statistics of the connection pool
+// PoolStats returns a snapshot of the primary pool and, when configured, the
+// replica pool, with counters relative to the last reset
+func (db *DBPool) PoolStats() (primary PoolStats, replica *PoolStats, since time.Time) {
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	primary = poolStats(db.Pool.Stat()).since(db.baseline)
+	if db.Replica != nil {
+		stats := poolStats(db.Replica.Pool.Stat()).since(db.replicaBase)
+		replica = &stats
+	}
+	return primary, replica, db.statsResetAt
+}
+
+// ResetStats restarts the pool counters and the query statistics reported by
+// PoolStats and Tracer.Stats. pgxpool counters only grow, so the current
+// values become the new baseline; the Prometheus counters are unaffected.
 func (db *DBPool) ResetStats() {
-	// Note: pgxpool.Stat doesn't have a Reset method in v5, so we can't reset stats directly
-	// This is a placeholder for future implementations if needed
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	db.baseline = poolStats(db.Pool.Stat())
+	if db.Replica != nil {
+		db.replicaBase = poolStats(db.Replica.Pool.Stat())
+	}
+	db.statsResetAt = time.Now()
+	if db.Tracer != nil {
+		db.Tracer.Reset()
+	}
+}
+
+// poolStats converts pgxpool statistics
+func poolStats(s *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		AcquiredConns:     s.AcquiredConns(),
+		IdleConns:         s.IdleConns(),
+		TotalConns:        s.TotalConns(),
+		MaxConns:          s.MaxConns(),
+		AcquireCount:      s.AcquireCount(),
+		CanceledAcquire:   s.CanceledAcquireCount(),
+		WaitCount:         s.EmptyAcquireCount(),
+		WaitDuration:      s.EmptyAcquireWaitTime(),
+		AcquireDuration:   s.AcquireDuration(),
+		NewConns:          s.NewConnsCount(),
+		LifetimeDestroyed: s.MaxLifetimeDestroyCount(),
+		IdleDestroyed:     s.MaxIdleDestroyCount(),
+	}
+}
+
+// since subtracts a baseline from the counters, keeping the gauges
+func (s PoolStats) since(base PoolStats) PoolStats {
+	s.AcquireCount -= base.AcquireCount
+	s.CanceledAcquire -= base.CanceledAcquire
+	s.WaitCount -= base.WaitCount
+	s.WaitDuration -= base.WaitDuration
+	s.AcquireDuration -= base.AcquireDuration
+	s.NewConns -= base.NewConns
+	s.LifetimeDestroyed -= base.LifetimeDestroyed
+	s.IdleDestroyed -= base.IdleDestroyed
+	return s
 }
 
 // InitGlobalDBPool initializes the global database pool with the given configuration
@@ -208,7 +339,7 @@ func InitGlobalDBPool(cfg *config.Config) (*DBPool, error) {
 	var err error
 	
 	poolOnce.Do(func() {
-		log.Println("Initializing global database connection pool...")
+		slog.Info("Initializing global database connection pool")
 		globalDBPool, err = NewDBPool(cfg)
 	})
 	
@@ -231,6 +362,6 @@ func CloseGlobalDBPool() {
 	if globalDBPool != nil {
 		globalDBPool.Close()
 		globalDBPool = nil
-		log.Println("Global database pool closed")
+		slog.Info("Global database pool closed")
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// FieldCipher encrypts designated columns before they are written and
+// decrypts them after they are read
+type FieldCipher interface {
+	Encrypt(field, plaintext string) (string, error)
+	Decrypt(field, value string) (string, error)
+	BlindIndex(field, value string) string
+}
+
+// Encrypted columns, named as the field bound to their ciphertext
+const (
+	fieldEmployeeAddress = "employees.address"
+)
+
+// plaintextCipher stores values unencrypted. It is used until a cipher is
+// configured, and its unkeyed blind index only keeps lookups working.
+type plaintextCipher struct{}
+
+// Encrypt returns the value unchanged
+func (plaintextCipher) Encrypt(field, plaintext string) (string, error) {
+	return plaintext, nil
+}
+
+// Decrypt returns the value unchanged
+func (plaintextCipher) Decrypt(field, value string) (string, error) {
+	return value, nil
+}
+
+// BlindIndex returns an unkeyed hash of the normalised value
+func (plaintextCipher) BlindIndex(field, value string) string {
+	normalised := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	if normalised == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(field + "\x00" + normalised))
+	return hex.EncodeToString(sum[:])
+}
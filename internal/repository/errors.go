@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNotFound is returned (wrapped) when a requested record does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrVersionConflict is returned (wrapped) when an update was based on an
+// outdated version of the record
+var ErrVersionConflict = errors.New("version conflict")
+
+// Constraint violation kinds, matched with errors.Is against a ConstraintError
+var (
+	ErrDuplicate            = errors.New("duplicate value")
+	ErrInvalidReference     = errors.New("referenced record does not exist")
+	ErrStillReferenced      = errors.New("record is still referenced")
+	ErrCheckViolation       = errors.New("value violates a check constraint")
+	ErrRequired             = errors.New("value is required")
+	ErrSensitiveFieldUpdate = errors.New("not authorized to modify sensitive field")
+)
+
+// PostgreSQL error codes handled by translatePgError
+const (
+	pgUniqueViolation       = "23505"
+	pgForeignKeyViolation   = "23503"
+	pgCheckViolation        = "23514"
+	pgNotNullViolation      = "23502"
+	pgInsufficientPrivilege = "42501"
+	pgRaiseException        = "P0001"
+)
+
+// sensitiveFieldsMessage is raised by the restrict_sensitive_fields_update trigger
+const sensitiveFieldsMessage = "Not authorized to modify sensitive employee fields"
+
+// constraintFields maps named constraints to the field they protect
+var constraintFields = map[string]string{
+	"employees_email_key":          "email",
+	"fk_employee_position":         "position_id",
+	"fk_employee_department":       "department_id",
+	"fk_employee_site":             "site_id",
+	"fk_employee_manager":          "manager_id",
+	"fk_department_lead":           "lead_id",
+	"fk_assessment_template":       "template_id",
+	"fk_assessment_employee":       "employee_id",
+	"fk_assessment_reviewer":       "reviewer_id",
+	"fk_goal_employee":             "employee_id",
+	"fk_checkin_goal":              "goal_id",
+	"goal_checkins_progress_check": "progress",
+
+	"employee_change_requests_pending_key": "employee_id",
+	"fk_change_request_employee":           "employee_id",
+	"change_request_changes_check":         "changes",
+}
+
+// detailKeyPattern extracts the column list from details like "Key (email)=(x) already exists."
+var detailKeyPattern = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// ConstraintError describes a database constraint violation in terms of the
+// offending field. It matches its Kind with errors.Is.
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Field      string
+	Constraint string
+	Err        error
+}
+
+// Error implements the error interface
+func (e *ConstraintError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s on %s", e.Kind, e.Table)
+	}
+	return fmt.Sprintf("%s: %s.%s", e.Kind, e.Table, e.Field)
+}
+
+// Is reports whether the target is the kind of violation
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying driver error
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// translatePgError converts PostgreSQL constraint violations into a
+// ConstraintError naming the offending field. Other errors are returned as is.
+func translatePgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	ce := &ConstraintError{
+		Table:      pgErr.TableName,
+		Field:      constraintField(pgErr),
+		Constraint: pgErr.ConstraintName,
+		Err:        err,
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		ce.Kind = ErrDuplicate
+	case pgForeignKeyViolation:
+		// Deleting a row that others point at reports the referencing table
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			ce.Kind = ErrStillReferenced
+		} else {
+			ce.Kind = ErrInvalidReference
+		}
+	case pgCheckViolation:
+		ce.Kind = ErrCheckViolation
+	case pgNotNullViolation:
+		ce.Kind = ErrRequired
+	case pgInsufficientPrivilege, pgRaiseException:
+		if pgErr.Message != sensitiveFieldsMessage {
+			return err
+		}
+		ce.Kind = ErrSensitiveFieldUpdate
+		if ce.Table == "" {
+			ce.Table = "employees"
+		}
+	default:
+		return err
+	}
+
+	return ce
+}
+
+// constraintField works out which field a violation refers to
+func constraintField(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	if field, ok := constraintFields[pgErr.ConstraintName]; ok {
+		return field
+	}
+	if m := detailKeyPattern.FindStringSubmatch(pgErr.Detail); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -2,9 +2,20 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
+// Tenant represents a company sharing the deployment
+type Tenant struct {
+	ID        string    `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Hostname  string    `json:"hostname,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // Employee represents an employee record
 type Employee struct {
 	ID              string    `json:"id"`
@@ -25,6 +36,46 @@ type Employee struct {
 	ProfilePicture  string    `json:"profile_picture_url,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
+}
+
+// EmployeeSearchResult is an employee found by a directory search, with the
+// names of their position, department and site
+type EmployeeSearchResult struct {
+	ID             string  `json:"id"`
+	DisplayName    string  `json:"display_name"`
+	Email          string  `json:"email"`
+	PositionTitle  string  `json:"position_title,omitempty"`
+	DepartmentName string  `json:"department_name,omitempty"`
+	SiteName       string  `json:"site_name,omitempty"`
+	ProfilePicture string  `json:"profile_picture_url,omitempty"`
+	Rank           float64 `json:"rank"`
+}
+
+// EmployeeSummary names an employee without their personal details
+type EmployeeSummary struct {
+	ID            string `json:"id"`
+	DisplayName   string `json:"display_name"`
+	Email         string `json:"email"`
+	PositionTitle string `json:"position_title,omitempty"`
+}
+
+// EmployeeChangeRequest is a requested change to an employee's sensitive
+// fields, applied once HR approves it. Changes maps field names to their new
+// values, with an empty string clearing the end date.
+type EmployeeChangeRequest struct {
+	ID          string            `json:"id"`
+	EmployeeID  string            `json:"employee_id"`
+	RequestedBy string            `json:"requested_by,omitempty"`
+	Changes     map[string]string `json:"changes"`
+	Reason      string            `json:"reason,omitempty"`
+	Status      string            `json:"status"`
+	ReviewedBy  string            `json:"reviewed_by,omitempty"`
+	ReviewNote  string            `json:"review_note,omitempty"`
+	ReviewedAt  time.Time         `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Version     int               `json:"version"`
 }
 
 // Position represents a job position
@@ -35,6 +86,7 @@ type Position struct {
 	Requirements string    `json:"requirements,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	Version      int       `json:"version"`
 }
 
 // Department represents a department
@@ -45,6 +97,7 @@ type Department struct {
 	LeadID      string    `json:"lead_id,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
 }
 
 // Site represents a physical location
@@ -55,6 +108,66 @@ type Site struct {
 	Address   string    `json:"address"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
+
+// AssessmentTemplate represents a reusable assessment form
+type AssessmentTemplate struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Version     int       `json:"version"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Assessment represents a performance assessment of an employee by a reviewer
+type Assessment struct {
+	ID          string    `json:"id"`
+	TemplateID  string    `json:"template_id"`
+	EmployeeID  string    `json:"employee_id"`
+	ReviewerID  string    `json:"reviewer_id"`
+	Status      string    `json:"status"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
+}
+
+// Goal represents an employee goal
+type Goal struct {
+	ID          string    `json:"id"`
+	EmployeeID  string    `json:"employee_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	TimeFrame   string    `json:"time_frame"`
+	Type        string    `json:"type"`
+	Status      string    `json:"status"`
+	DueDate     time.Time `json:"due_date,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
+}
+
+// GoalCheckIn represents a progress update on a goal
+type GoalCheckIn struct {
+	ID        string    `json:"id"`
+	GoalID    string    `json:"goal_id"`
+	Note      string    `json:"note,omitempty"`
+	Progress  int       `json:"progress"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditLog represents a change recorded by the audit trigger
+type AuditLog struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id,omitempty"`
+	Action    string          `json:"action"`
+	TableName string          `json:"table_name"`
+	RecordID  string          `json:"record_id"`
+	Changes   json.RawMessage `json:"changes,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 // EmployeeRepository defines operations for working with employees
@@ -65,12 +178,27 @@ type EmployeeRepository interface {
 	// Get an employee by ID
 	GetByID(ctx context.Context, id string) (*Employee, error)
 	
+	// Get an employee by ID, even if they were soft deleted
+	GetByIDIncludingDeleted(ctx context.Context, id string) (*Employee, error)
+	
 	// Update an employee
 	Update(ctx context.Context, employee *Employee) error
 	
-	// Delete an employee
+	// Soft delete an employee
 	Delete(ctx context.Context, id string) error
 	
+	// Restore a soft-deleted employee
+	Restore(ctx context.Context, id string) error
+	
+	// Permanently remove employees deleted before the cutoff, with their goals and assessments
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	
+	// Replace an employee's personal data with placeholders
+	Anonymise(ctx context.Context, id string) error
+	
+	// Rewrite encrypted columns of every employee under the active key
+	Reencrypt(ctx context.Context) (int64, error)
+	
 	// List employees with optional filters
 	List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*Employee, int64, error)
 	
@@ -79,6 +207,18 @@ type EmployeeRepository interface {
 	
 	// Get employees by department ID
 	GetByDepartment(ctx context.Context, departmentID string) ([]*Employee, error)
+	
+	// Count employees with active status that have not been deleted
+	CountActive(ctx context.Context) (int64, error)
+	
+	// Search employees by name, email, position, department and site, best match first
+	Search(ctx context.Context, query string, limit int) ([]*EmployeeSearchResult, error)
+	
+	// Update the fields employees maintain themselves: display name, address and picture
+	UpdateProfile(ctx context.Context, employee *Employee) error
+	
+	// List the managers above an employee, nearest first
+	ManagerChain(ctx context.Context, id string) ([]*EmployeeSummary, error)
 }
 
 // PositionRepository defines operations for working with positions
@@ -88,6 +228,12 @@ type PositionRepository interface {
 	Update(ctx context.Context, position *Position) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*Position, int64, error)
+
+	// Restore a soft-deleted position
+	Restore(ctx context.Context, id string) error
+
+	// Permanently remove unreferenced positions deleted before the cutoff
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
 
 // DepartmentRepository defines operations for working with departments
@@ -97,6 +243,12 @@ type DepartmentRepository interface {
 	Update(ctx context.Context, department *Department) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*Department, int64, error)
+
+	// Restore a soft-deleted department
+	Restore(ctx context.Context, id string) error
+
+	// Permanently remove unreferenced departments deleted before the cutoff
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
 
 // SiteRepository defines operations for working with sites
@@ -106,16 +258,98 @@ type SiteRepository interface {
 	Update(ctx context.Context, site *Site) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*Site, int64, error)
+
+	// Restore a soft-deleted site
+	Restore(ctx context.Context, id string) error
+
+	// Permanently remove unreferenced sites deleted before the cutoff
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AssessmentRepository defines operations for working with assessments
+type AssessmentRepository interface {
+	Create(ctx context.Context, assessment *Assessment) (string, error)
+	GetByID(ctx context.Context, id string) (*Assessment, error)
+	Update(ctx context.Context, assessment *Assessment) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*Assessment, int64, error)
+
+	// Get an assessment template by ID
+	GetTemplateByID(ctx context.Context, id string) (*AssessmentTemplate, error)
+
+	// Count assessments that are neither completed nor cancelled, by status
+	CountOpenByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// GoalRepository defines operations for working with goals and their check-ins
+type GoalRepository interface {
+	Create(ctx context.Context, goal *Goal) (string, error)
+	GetByID(ctx context.Context, id string) (*Goal, error)
+	Update(ctx context.Context, goal *Goal) error
+	Delete(ctx context.Context, id string) error
+	GetByEmployee(ctx context.Context, employeeID string) ([]*Goal, error)
+
+	// Record a progress check-in on a goal, filling in its creation time
+	AddCheckIn(ctx context.Context, checkIn *GoalCheckIn) (string, error)
+
+	// List check-ins for a goal, newest first
+	ListCheckIns(ctx context.Context, goalID string) ([]*GoalCheckIn, error)
+
+	// Count active goals whose due date is before the given day
+	CountOverdue(ctx context.Context, asOf time.Time) (int64, error)
+}
+
+// EmployeeChangeRequestRepository defines operations for requested changes to
+// sensitive employee fields
+type EmployeeChangeRequestRepository interface {
+	Create(ctx context.Context, request *EmployeeChangeRequest) (string, error)
+	GetByID(ctx context.Context, id string) (*EmployeeChangeRequest, error)
+
+	// List requests with the given status, or all when empty, oldest first
+	List(ctx context.Context, status string, limit, offset int) ([]*EmployeeChangeRequest, int64, error)
+
+	// List the requests for an employee, newest first
+	GetByEmployee(ctx context.Context, employeeID string) ([]*EmployeeChangeRequest, error)
+
+	// Apply a pending request to its employee, who must still be at the
+	// expected version, and mark it approved by the acting user
+	Approve(ctx context.Context, id string, employeeVersion int, note string) error
+
+	// Mark a pending request rejected by the acting user
+	Reject(ctx context.Context, id string, note string) error
+}
+
+// TenantRepository defines operations for looking up tenants
+type TenantRepository interface {
+	GetByHostname(ctx context.Context, hostname string) (*Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (*Tenant, error)
+	List(ctx context.Context) ([]*Tenant, error)
+}
+
+// AuditLogRepository defines operations for working with audit log entries
+type AuditLogRepository interface {
+	// List the entries recorded for any of the given records or made by the
+	// user, oldest first
+	ListBySubject(ctx context.Context, userID string, recordIDs []string) ([]*AuditLog, error)
+
+	// Redact the given fields from the changes recorded for records of a table
+	RedactFields(ctx context.Context, tableName string, recordIDs []string, fields []string) (int64, error)
 }
 
 // RepositoryFactory defines the repository factory interface
 type RepositoryFactory interface {
+	Tenants() TenantRepository
 	Employees() EmployeeRepository
 	Positions() PositionRepository
 	Departments() DepartmentRepository
 	Sites() SiteRepository
+	Assessments() AssessmentRepository
+	Goals() GoalRepository
+	AuditLogs() AuditLogRepository
+	ChangeRequests() EmployeeChangeRequestRepository
 	
-	// WithTransaction starts a new transaction and returns a RepositoryFactory that uses it
+	// WithTransaction starts a new transaction, scoped to the tenant and actor
+	// carried by the context, and returns a RepositoryFactory that uses it
 	WithTransaction(ctx context.Context) (RepositoryFactory, error)
 	
 	// Commit commits the current transaction
@@ -1,27 +1,57 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gfurduy/byebob/config"
+	"github.com/gfurduy/byebob/migrations"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// DefaultMigrationsPath is the source directory new migrations are created in
+const DefaultMigrationsPath = "migrations/postgres"
+
+// migrationLockID is the Postgres advisory lock key held while migrating on
+// startup, so replicas starting together apply migrations one at a time
+const migrationLockID int64 = 0x6279656d6967 // "byemig"
+
+// migrationFilePattern matches the up file of a sequential migration
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
 // MigrationManager handles database migrations
 type MigrationManager struct {
 	pool   *pgxpool.Pool
 	config *config.Config
 }
 
+// MigrationInfo describes a migration available in the migrations directory
+type MigrationInfo struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+}
+
+// MigrationStatus reports the applied version and the migrations still pending
+type MigrationStatus struct {
+	Version uint            `json:"version"`
+	Dirty   bool            `json:"dirty"`
+	Pending []MigrationInfo `json:"pending"`
+}
+
 // NewMigrationManager creates a new migration manager
 func NewMigrationManager(pool *pgxpool.Pool, cfg *config.Config) *MigrationManager {
 	return &MigrationManager{
@@ -30,74 +60,294 @@ func NewMigrationManager(pool *pgxpool.Pool, cfg *config.Config) *MigrationManag
 	}
 }
 
-// RunMigrations applies all pending migrations
-func (m *MigrationManager) RunMigrations(migrationsPath string) error {
-	log.Println("Running database migrations from:", migrationsPath)
+// openSource opens the migrations in the given directory, or the migrations
+// embedded in the binary when the path is empty
+func openSource(migrationsPath string) (string, source.Driver, error) {
+	if migrationsPath == "" {
+		src, err := iofs.New(migrations.FS, migrations.PostgresDir)
+		return "iofs", src, err
+	}
+	src, err := source.Open(fmt.Sprintf("file://%s", migrationsPath))
+	return "file", src, err
+}
+
+// describeSource names the migrations source for log messages
+func describeSource(migrationsPath string) string {
+	if migrationsPath == "" {
+		return "embedded migrations"
+	}
+	return migrationsPath
+}
+
+// migrator opens a migrate instance over the pool for the migrations source.
+// The returned function closes it.
+func (m *MigrationManager) migrator(migrationsPath string) (*migrate.Migrate, func(), error) {
+	sourceName, src, err := openSource(migrationsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
 
 	// Create a sql.DB instance from the pgx pool
 	db := stdlib.OpenDBFromPool(m.pool)
-	defer db.Close()
 
 	// Create the postgres driver
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("failed to create database driver: %w", err)
+		src.Close()
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to create database driver: %w", err)
 	}
 
 	// Create the migrate instance
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		"postgres", driver)
+	migrator, err := migrate.NewWithInstance(sourceName, src, "postgres", driver)
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		src.Close()
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to create migrator: %w", err)
 	}
 
-	// Run migrations
-	if err := migrator.Up(); err != nil {
+	return migrator, func() {
+		_, _ = migrator.Close()
+		db.Close()
+	}, nil
+}
+
+// RunMigrations applies all pending migrations
+func (m *MigrationManager) RunMigrations(migrationsPath string) error {
+	slog.Info("Running database migrations", "source", describeSource(migrationsPath))
+	return m.Up(migrationsPath, 0)
+}
+
+// RunMigrationsLocked applies all pending migrations while holding an
+// advisory lock, so that only one replica migrates at a time and the others
+// wait for it and then find nothing to apply
+func (m *MigrationManager) RunMigrationsLocked(ctx context.Context, migrationsPath string) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migration lock: %w", err)
+	}
+	defer conn.Release()
+
+	slog.Info("Waiting for migration lock")
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			slog.Error("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	return m.RunMigrations(migrationsPath)
+}
+
+// Up applies the next n pending migrations, or all of them when n is 0
+func (m *MigrationManager) Up(migrationsPath string, n int) error {
+	if n < 0 {
+		return fmt.Errorf("number of migrations must not be negative")
+	}
+
+	migrator, closeMigrator, err := m.migrator(migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer closeMigrator()
+
+	if n == 0 {
+		err = migrator.Up()
+	} else {
+		err = migrator.Steps(n)
+	}
+	if err != nil {
 		if errors.Is(err, migrate.ErrNoChange) {
-			log.Println("No migrations to apply")
+			slog.Info("No migrations to apply")
 			return nil
 		}
 		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
-	log.Println("Migrations applied successfully")
+	slog.Info("Migrations applied successfully")
+	return nil
+}
+
+// Down rolls back the last n applied migrations
+func (m *MigrationManager) Down(migrationsPath string, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to roll back must be positive")
+	}
+
+	migrator, closeMigrator, err := m.migrator(migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer closeMigrator()
+
+	if err := migrator.Steps(-n); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			slog.Info("No migrations to roll back")
+			return nil
+		}
+		return fmt.Errorf("failed to rollback migration: %w", err)
+	}
+
+	slog.Info("Rolled back migrations successfully", "count", n)
+	return nil
+}
+
+// Goto migrates up or down to the given version
+func (m *MigrationManager) Goto(migrationsPath string, version uint) error {
+	migrator, closeMigrator, err := m.migrator(migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer closeMigrator()
+
+	if err := migrator.Migrate(version); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			slog.Info("Already at version", "version", version)
+			return nil
+		}
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+
+	slog.Info("Migrated to version", "version", version)
+	return nil
+}
+
+// Force records the given version as applied and clears the dirty flag
+// without running any migration. Use it after fixing a failed migration by hand.
+func (m *MigrationManager) Force(migrationsPath string, version int) error {
+	migrator, closeMigrator, err := m.migrator(migrationsPath)
+	if err != nil {
+		return err
+	}
+	defer closeMigrator()
+
+	if err := migrator.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+
+	slog.Info("Forced version", "version", version)
 	return nil
 }
 
-// CreateMigration creates a new migration
+// Status reports the applied version and the migrations not yet applied
+func (m *MigrationManager) Status(migrationsPath string) (*MigrationStatus, error) {
+	version, dirty, err := m.GetMigrationVersion(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := ListMigrations(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{Version: version, Dirty: dirty, Pending: []MigrationInfo{}}
+	for _, migration := range available {
+		if migration.Version > version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// ListMigrations returns the available migrations in version order, read from
+// the directory or, when the path is empty, from the binary
+func ListMigrations(migrationsPath string) ([]MigrationInfo, error) {
+	_, src, err := openSource(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer src.Close()
+
+	var available []MigrationInfo
+	version, err := src.First()
+	for err == nil {
+		name := ""
+		if r, identifier, readErr := src.ReadUp(version); readErr == nil {
+			r.Close()
+			name = identifier
+		}
+		available = append(available, MigrationInfo{Version: version, Name: name})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	return available, nil
+}
+
+// LatestMigration returns the version of the newest available migration, or
+// 0 when there is none
+func LatestMigration(migrationsPath string) (uint, error) {
+	available, err := ListMigrations(migrationsPath)
+	if err != nil || len(available) == 0 {
+		return 0, err
+	}
+	return available[len(available)-1].Version, nil
+}
+
+// CheckVersion fails when the last migration left the database dirty or the
+// database is behind the expected version. A newer version is accepted, as
+// during a rolling deploy the new release migrates before the old one stops.
+func (m *MigrationManager) CheckVersion(migrationsPath string, expected uint) error {
+	version, dirty, err := m.GetMigrationVersion(migrationsPath)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed and left the database dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("database is at version %d, expected %d", version, expected)
+	}
+	return nil
+}
+
+// CreateMigration creates a new migration numbered after the latest one. New
+// migrations are written to DefaultMigrationsPath when the path is empty.
 func (m *MigrationManager) CreateMigration(name, migrationsPath string) error {
-	log.Printf("Creating new migration '%s' in %s\n", name, migrationsPath)
-	
+	if migrationsPath == "" {
+		migrationsPath = DefaultMigrationsPath
+	}
+	slog.Info("Creating new migration", "name", name, "path", migrationsPath)
+
 	// Validate migration name
-	if name == "" {
+	if strings.TrimSpace(name) == "" {
 		return fmt.Errorf("migration name cannot be empty")
 	}
-	
+
 	// Normalize migration name to use underscores instead of spaces
-	normalizedName := strings.ReplaceAll(name, " ", "_")
+	normalizedName := strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
 	normalizedName = strings.ToLower(normalizedName)
-	
+
+	sequence, err := nextMigrationSequence(migrationsPath)
+	if err != nil {
+		return err
+	}
+
 	// Create up and down migration files
-	timestamp := time.Now().Unix()
-	upMigrationFileName := fmt.Sprintf("%s/%d_%s.up.sql", migrationsPath, timestamp, normalizedName)
-	downMigrationFileName := fmt.Sprintf("%s/%d_%s.down.sql", migrationsPath, timestamp, normalizedName)
-	
+	upMigrationFileName := fmt.Sprintf("%s/%03d_%s.up.sql", migrationsPath, sequence, normalizedName)
+	downMigrationFileName := fmt.Sprintf("%s/%03d_%s.down.sql", migrationsPath, sequence, normalizedName)
+
 	// Write template content to the up migration file
 	upContent := fmt.Sprintf(`-- Migration: %s (up)
 -- Created at: %s
 
 BEGIN;
 
--- Add your schema changes here
+-- Add your schema changes here. Check them with "byebob migrate lint" before
+-- committing; CREATE INDEX CONCURRENTLY needs a migration of its own holding
+-- only that statement, without BEGIN/COMMIT.
 
 COMMIT;
-`, name, time.Now().Format(time.RFC3339))
+`, normalizedName, time.Now().UTC().Format(time.RFC3339))
 
 	if err := os.WriteFile(upMigrationFileName, []byte(upContent), 0644); err != nil {
 		return fmt.Errorf("failed to create up migration file: %w", err)
 	}
-	
+
 	// Write template content to the down migration file
 	downContent := fmt.Sprintf(`-- Migration: %s (down)
 -- Created at: %s
@@ -107,68 +357,48 @@ BEGIN;
 -- Add your rollback commands here
 
 COMMIT;
-`, name, time.Now().Format(time.RFC3339))
+`, normalizedName, time.Now().UTC().Format(time.RFC3339))
 
 	if err := os.WriteFile(downMigrationFileName, []byte(downContent), 0644); err != nil {
 		return fmt.Errorf("failed to create down migration file: %w", err)
 	}
-	
-	log.Printf("Created migration files:\n- %s\n- %s\n", upMigrationFileName, downMigrationFileName)
+
+	slog.Info("Created migration files", "up", upMigrationFileName, "down", downMigrationFileName)
 	return nil
 }
 
-// RollbackMigration rolls back the last migration
-func (m *MigrationManager) RollbackMigration(migrationsPath string) error {
-	// Create a sql.DB instance from the pgx pool
-	db := stdlib.OpenDBFromPool(m.pool)
-	defer db.Close()
-
-	// Create the postgres driver
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create database driver: %w", err)
-	}
-
-	// Create the migrate instance
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		"postgres", driver)
+// nextMigrationSequence returns the number following the latest migration
+func nextMigrationSequence(migrationsPath string) (int, error) {
+	entries, err := os.ReadDir(migrationsPath)
 	if err != nil {
-		return fmt.Errorf("failed to create migrator: %w", err)
+		return 0, fmt.Errorf("failed to read migrations directory: %w", err)
 	}
 
-	// Rollback one migration
-	if err := migrator.Steps(-1); err != nil {
-		if errors.Is(err, migrate.ErrNoChange) {
-			log.Println("No migrations to roll back")
-			return nil
+	latest := 0
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(filepath.Base(entry.Name()))
+		if match == nil {
+			continue
+		}
+		if sequence, err := strconv.Atoi(match[1]); err == nil && sequence > latest {
+			latest = sequence
 		}
-		return fmt.Errorf("failed to rollback migration: %w", err)
 	}
+	return latest + 1, nil
+}
 
-	log.Println("Migration rolled back successfully")
-	return nil
+// RollbackMigration rolls back the last migration
+func (m *MigrationManager) RollbackMigration(migrationsPath string) error {
+	return m.Down(migrationsPath, 1)
 }
 
 // GetMigrationVersion gets the current migration version
 func (m *MigrationManager) GetMigrationVersion(migrationsPath string) (uint, bool, error) {
-	// Create a sql.DB instance from the pgx pool
-	db := stdlib.OpenDBFromPool(m.pool)
-	defer db.Close()
-
-	// Create the postgres driver
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return 0, false, fmt.Errorf("failed to create database driver: %w", err)
-	}
-
-	// Create the migrate instance
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		"postgres", driver)
+	migrator, closeMigrator, err := m.migrator(migrationsPath)
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to create migrator: %w", err)
+		return 0, false, err
 	}
+	defer closeMigrator()
 
 	// Get current version
 	version, dirty, err := migrator.Version()
@@ -180,4 +410,4 @@ func (m *MigrationManager) GetMigrationVersion(migrationsPath string) (uint, boo
 	}
 
 	return version, dirty, nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gfurduy/byebob/migrations"
+)
+
+// Migration lint rules. A statement can opt out of a rule with a preceding
+// "-- lint:ignore <rule>[,<rule>...]" comment.
+const (
+	LintDropTable         = "drop-table"
+	LintDropColumn        = "drop-column"
+	LintBlockingIndex     = "blocking-index"
+	LintVolatileDefault   = "volatile-default"
+	LintMissingDown       = "missing-down"
+	LintHardcodedPassword = "hardcoded-password"
+)
+
+// largeTables are the tables expected to grow with usage, where building an
+// index without CONCURRENTLY blocks writes for too long
+var largeTables = map[string]bool{
+	"employees":     true,
+	"assessments":   true,
+	"goals":         true,
+	"goal_checkins": true,
+	"audit_logs":    true,
+}
+
+// volatileFunctions are defaults that force ADD COLUMN to rewrite the table
+var volatileFunctions = []string{
+	"RANDOM(", "CLOCK_TIMESTAMP(", "TIMEOFDAY(", "NEXTVAL(",
+	"GEN_RANDOM_UUID(", "UUID_GENERATE_V1(", "UUID_GENERATE_V4(",
+}
+
+var (
+	dropTablePattern   = regexp.MustCompile(`^DROP\s+TABLE\b`)
+	alterTablePattern  = regexp.MustCompile(`^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w."]+)`)
+	alterDropPattern   = regexp.MustCompile(`\bDROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?([\w"]+)`)
+	createIndexPattern = regexp.MustCompile(`^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(?:[\w."]+\s+)?ON\s+(?:ONLY\s+)?([\w."]+)`)
+	createTablePattern = regexp.MustCompile(`^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."]+)`)
+	addColumnPattern   = regexp.MustCompile(`\bADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?[\w"]+\s+[^,]*?\bDEFAULT\s+([^,]+)`)
+	passwordPattern    = regexp.MustCompile(`\b(?:CREATE|ALTER)\s+(?:ROLE|USER)\b[^;]*?\bPASSWORD\s+'`)
+	ignorePattern      = regexp.MustCompile(`lint:ignore\s+([\w,\- ]+)`)
+)
+
+// alterDropKeywords are ALTER TABLE ... DROP targets that are not columns
+var alterDropKeywords = map[string]bool{
+	"CONSTRAINT": true, "DEFAULT": true, "NOT": true, "IDENTITY": true, "EXPRESSION": true,
+}
+
+// MigrationIssue is a risky operation found in a migration
+type MigrationIssue struct {
+	Version uint   `json:"version"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String formats the issue like a compiler diagnostic
+func (i MigrationIssue) String() string {
+	return fmt.Sprintf("%s:%d: [%s] %s", i.File, i.Line, i.Rule, i.Message)
+}
+
+// LintPending checks the migrations the database has not applied yet
+func (m *MigrationManager) LintPending(migrationsPath string) ([]MigrationIssue, error) {
+	version, _, err := m.GetMigrationVersion(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	return LintMigrations(migrationsPath, version)
+}
+
+// LintMigrations checks the up migrations newer than the given version, read
+// from the directory or, when the path is empty, from the binary
+func LintMigrations(migrationsPath string, after uint) ([]MigrationIssue, error) {
+	fsys, err := migrationFiles(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+
+	var issues []MigrationIssue
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || uint(version) <= after {
+			continue
+		}
+
+		dir := migrationsPath
+		if dir == "" {
+			dir = DefaultMigrationsPath
+		}
+		file := path.Join(dir, entry.Name())
+		if !names[strings.TrimSuffix(entry.Name(), ".up.sql")+".down.sql"] {
+			issues = append(issues, MigrationIssue{
+				Version: uint(version), File: file, Line: 1, Rule: LintMissingDown,
+				Message: "no matching .down.sql file, so the migration cannot be rolled back",
+			})
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		for _, issue := range LintSQL(string(content)) {
+			issue.Version = uint(version)
+			issue.File = file
+			issues = append(issues, issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(a, b int) bool {
+		if issues[a].Version != issues[b].Version {
+			return issues[a].Version < issues[b].Version
+		}
+		return issues[a].Line < issues[b].Line
+	})
+	return issues, nil
+}
+
+// migrationFiles returns the migrations directory as a file system
+func migrationFiles(migrationsPath string) (fs.FS, error) {
+	if migrationsPath == "" {
+		return fs.Sub(migrations.FS, migrations.PostgresDir)
+	}
+	return os.DirFS(migrationsPath), nil
+}
+
+// LintSQL checks the statements of an up migration for risky operations
+func LintSQL(sql string) []MigrationIssue {
+	statements := splitStatements(sql)
+
+	// Indexes on tables created by the same migration cannot block anyone
+	created := make(map[string]bool)
+	for _, stmt := range statements {
+		if match := createTablePattern.FindStringSubmatch(stmt.normalized); match != nil {
+			created[tableName(match[1])] = true
+		}
+	}
+
+	var issues []MigrationIssue
+	report := func(stmt statement, rule, message string) {
+		if stmt.ignored[rule] {
+			return
+		}
+		issues = append(issues, MigrationIssue{Line: stmt.line, Rule: rule, Message: message})
+	}
+
+	for _, stmt := range statements {
+		s := stmt.normalized
+
+		if dropTablePattern.MatchString(s) {
+			report(stmt, LintDropTable, "DROP TABLE destroys data; deploy code that no longer uses the table first")
+		}
+
+		if match := alterTablePattern.FindStringSubmatch(s); match != nil {
+			table := tableName(match[1])
+			for _, drop := range alterDropPattern.FindAllStringSubmatch(s, -1) {
+				if !alterDropKeywords[strings.Trim(drop[1], `"`)] {
+					report(stmt, LintDropColumn, fmt.Sprintf("dropping column %s.%s destroys data and breaks code still reading it", table, strings.ToLower(drop[1])))
+				}
+			}
+			for _, add := range addColumnPattern.FindAllStringSubmatch(s, -1) {
+				if isVolatile(add[1]) {
+					report(stmt, LintVolatileDefault, fmt.Sprintf("ADD COLUMN on %s with a volatile default rewrites the whole table under an exclusive lock; add the column without a default and backfill it", table))
+				}
+			}
+		}
+
+		if match := createIndexPattern.FindStringSubmatch(s); match != nil && match[1] == "" {
+			table := tableName(match[2])
+			if largeTables[table] && !created[table] {
+				report(stmt, LintBlockingIndex, fmt.Sprintf("CREATE INDEX on %s blocks writes while it builds; use CREATE INDEX CONCURRENTLY in a migration without BEGIN/COMMIT", table))
+			}
+		}
+
+		if passwordPattern.MatchString(s) {
+			report(stmt, LintHardcodedPassword, "role password is hard-coded in the migration; create the role without a password and set it outside version control")
+		}
+	}
+	return issues
+}
+
+// isVolatile reports whether a default expression calls a volatile function
+func isVolatile(expr string) bool {
+	for _, fn := range volatileFunctions {
+		if strings.Contains(strings.ReplaceAll(expr, " ", ""), fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableName normalises a possibly schema-qualified, quoted table name
+func tableName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, `"`, ""))
+	return strings.TrimPrefix(name, "public.")
+}
+
+// statement is one SQL statement of a migration
+type statement struct {
+	line       int
+	normalized string
+	ignored    map[string]bool
+}
+
+// splitStatements splits SQL on semicolons outside quotes, comments and
+// dollar-quoted bodies. Each statement is upper-cased with comments removed
+// and whitespace collapsed, and remembers the lint:ignore comments before it.
+func splitStatements(sql string) []statement {
+	var (
+		statements []statement
+		current    strings.Builder
+		ignored    = map[string]bool{}
+		line       = 1
+		startLine  = 0
+	)
+
+	flush := func() {
+		text := strings.Join(strings.Fields(current.String()), " ")
+		if text != "" {
+			statements = append(statements, statement{line: startLine, normalized: strings.ToUpper(text), ignored: ignored})
+		}
+		current.Reset()
+		ignored = map[string]bool{}
+		startLine = 0
+	}
+	write := func(s string) {
+		if startLine == 0 && strings.TrimSpace(s) != "" {
+			startLine = line
+		}
+		current.WriteString(s)
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\n':
+			current.WriteByte(' ')
+			line++
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			if match := ignorePattern.FindStringSubmatch(sql[i : i+end]); match != nil {
+				for _, rule := range strings.Split(match[1], ",") {
+					ignored[strings.TrimSpace(rule)] = true
+				}
+			}
+			current.WriteByte(' ')
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 4
+			}
+			comment := sql[i : i+end+4]
+			line += strings.Count(comment, "\n")
+			current.WriteByte(' ')
+			i += len(comment)
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				end = len(sql) - i - 2
+			}
+			quoted := sql[i : i+end+2]
+			write(quoted)
+			line += strings.Count(quoted, "\n")
+			i += len(quoted)
+		case c == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				write(string(c))
+				i++
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i - 2*len(tag)
+			}
+			body := sql[i : i+end+2*len(tag)]
+			write(body)
+			line += strings.Count(body, "\n")
+			i += len(body)
+		case c == ';':
+			flush()
+			i++
+		default:
+			write(string(c))
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string, if any
+func dollarTag(s string) string {
+	end := strings.IndexByte(s[1:], '$')
+	if end < 0 {
+		return ""
+	}
+	tag := s[:end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	return tag
+}
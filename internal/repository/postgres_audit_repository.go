@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// redactedValue replaces personal data in audit log changes
+const redactedValue = "[redacted]"
+
+// PostgresAuditLogRepository implements AuditLogRepository for PostgreSQL
+type PostgresAuditLogRepository struct {
+	factory *PostgresFactory
+}
+
+// ListBySubject retrieves the audit log entries for the given records and
+// those made by the user
+func (r *PostgresAuditLogRepository) ListBySubject(ctx context.Context, userID string, recordIDs []string) ([]*AuditLog, error) {
+	query := `
+		SELECT id, COALESCE(user_id::text, ''), action, table_name, COALESCE(record_id::text, ''),
+			changes, created_at
+		FROM audit_logs
+		WHERE record_id = ANY($1::uuid[]) OR user_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, query, recordIDs, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []*AuditLog{}
+	for rows.Next() {
+		var log AuditLog
+		err := rows.Scan(
+			&log.ID, &log.UserID, &log.Action, &log.TableName, &log.RecordID,
+			&log.Changes, &log.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+
+		logs = append(logs, &log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log rows: %w", err)
+	}
+
+	return logs, nil
+}
+
+// RedactFields overwrites the given fields in the changes recorded for
+// records of a table, leaving the entries themselves and their other fields
+// in place
+func (r *PostgresAuditLogRepository) RedactFields(ctx context.Context, tableName string, recordIDs []string, fields []string) (int64, error) {
+	query := `
+		UPDATE audit_logs
+		SET changes = (
+			SELECT jsonb_object_agg(key, CASE WHEN key = ANY($3) THEN to_jsonb($4::text) ELSE value END)
+			FROM jsonb_each(changes)
+		)
+		WHERE table_name = $1 AND record_id = ANY($2::uuid[]) AND changes ?| $3
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, tableName, recordIDs, fields, redactedValue)
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact audit logs: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
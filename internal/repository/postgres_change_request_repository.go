@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes raised by approve_employee_change_request
+const (
+	pgNoDataFound          = "P0002"
+	pgSerializationFailure = "40001"
+)
+
+// changeRequestColumns are the columns scanned by scanChangeRequest
+const changeRequestColumns = `
+	id, employee_id, COALESCE(requested_by::text, ''), changes, COALESCE(reason, ''), status,
+	COALESCE(reviewed_by::text, ''), COALESCE(review_note, ''), reviewed_at,
+	created_at, updated_at, version
+`
+
+// PostgresEmployeeChangeRequestRepository implements
+// EmployeeChangeRequestRepository for PostgreSQL
+type PostgresEmployeeChangeRequestRepository struct {
+	factory *PostgresFactory
+}
+
+// Create records a pending change request on behalf of the acting user
+func (r *PostgresEmployeeChangeRequestRepository) Create(ctx context.Context, request *EmployeeChangeRequest) (string, error) {
+	query := `
+		INSERT INTO employee_change_requests (employee_id, requested_by, changes, reason)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, NULLIF($4, ''))
+		RETURNING id
+	`
+
+	var id string
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		request.EmployeeID, request.RequestedBy, request.Changes, request.Reason,
+	).Scan(&id)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create change request: %w", translatePgError(err))
+	}
+
+	return id, nil
+}
+
+// GetByID retrieves a change request by ID
+func (r *PostgresEmployeeChangeRequestRepository) GetByID(ctx context.Context, id string) (*EmployeeChangeRequest, error) {
+	query := `SELECT ` + changeRequestColumns + ` FROM employee_change_requests WHERE id = $1`
+
+	request, err := scanChangeRequest(r.factory.getQueryer().QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: change request %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get change request: %w", err)
+	}
+
+	return request, nil
+}
+
+// List retrieves a page of change requests with the given status, oldest
+// first so that HR reviews them in the order they were made
+func (r *PostgresEmployeeChangeRequestRepository) List(ctx context.Context, status string, limit, offset int) ([]*EmployeeChangeRequest, int64, error) {
+	where := ` WHERE ($1 = '' OR status = $1)`
+
+	var total int64
+	err := r.factory.getQueryer().QueryRow(ctx, `SELECT COUNT(*) FROM employee_change_requests`+where, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count change requests: %w", err)
+	}
+
+	query := `SELECT ` + changeRequestColumns + ` FROM employee_change_requests` + where +
+		` ORDER BY created_at, id LIMIT $2 OFFSET $3`
+	requests, err := r.query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// GetByEmployee retrieves the change requests for an employee, newest first
+func (r *PostgresEmployeeChangeRequestRepository) GetByEmployee(ctx context.Context, employeeID string) ([]*EmployeeChangeRequest, error) {
+	query := `SELECT ` + changeRequestColumns + ` FROM employee_change_requests
+		WHERE employee_id = $1
+		ORDER BY created_at DESC, id`
+
+	return r.query(ctx, query, employeeID)
+}
+
+// Approve applies a pending change request through
+// approve_employee_change_request, the only way the application may change
+// sensitive employee fields
+func (r *PostgresEmployeeChangeRequestRepository) Approve(ctx context.Context, id string, employeeVersion int, note string) error {
+	query := `CALL approve_employee_change_request($1, $2, NULLIF($3, ''))`
+
+	if _, err := r.factory.getQueryer().Exec(ctx, query, id, employeeVersion, note); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgNoDataFound:
+				return fmt.Errorf("%w: pending change request %s", ErrNotFound, id)
+			case pgSerializationFailure:
+				return fmt.Errorf("%w: %s", ErrVersionConflict, pgErr.Message)
+			}
+		}
+		return fmt.Errorf("failed to approve change request: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Reject marks a pending change request rejected by the acting user
+func (r *PostgresEmployeeChangeRequestRepository) Reject(ctx context.Context, id string, note string) error {
+	query := `
+		UPDATE employee_change_requests
+		SET status = 'rejected', reviewed_by = app_user_id(), review_note = NULLIF($2, ''),
+			reviewed_at = NOW(), updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND status = 'pending'
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id, note)
+	if err != nil {
+		return fmt.Errorf("failed to reject change request: %w", translatePgError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: pending change request %s", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// query runs a change request query and scans every row
+func (r *PostgresEmployeeChangeRequestRepository) query(ctx context.Context, query string, args ...interface{}) ([]*EmployeeChangeRequest, error) {
+	rows, err := r.factory.getQueryer().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*EmployeeChangeRequest{}
+	for rows.Next() {
+		request, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan change request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating change request rows: %w", err)
+	}
+
+	return requests, nil
+}
+
+// scanChangeRequest scans a row selected with changeRequestColumns
+func scanChangeRequest(row pgx.Row) (*EmployeeChangeRequest, error) {
+	var request EmployeeChangeRequest
+	var reviewedAt sql.NullTime
+
+	err := row.Scan(
+		&request.ID, &request.EmployeeID, &request.RequestedBy, &request.Changes, &request.Reason,
+		&request.Status, &request.ReviewedBy, &request.ReviewNote, &reviewedAt,
+		&request.CreatedAt, &request.UpdatedAt, &request.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewedAt.Valid {
+		request.ReviewedAt = reviewedAt.Time
+	}
+	return &request, nil
+}
@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresAssessmentRepository implements AssessmentRepository for PostgreSQL
+type PostgresAssessmentRepository struct {
+	factory *PostgresFactory
+}
+
+// Create creates a new assessment
+func (r *PostgresAssessmentRepository) Create(ctx context.Context, assessment *Assessment) (string, error) {
+	query := `
+		INSERT INTO assessments (
+			template_id, employee_id, reviewer_id, status
+		) VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var id string
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		assessment.TemplateID, assessment.EmployeeID, assessment.ReviewerID, assessment.Status,
+	).Scan(&id)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create assessment: %w", translatePgError(err))
+	}
+
+	return id, nil
+}
+
+// GetByID retrieves an assessment by ID
+func (r *PostgresAssessmentRepository) GetByID(ctx context.Context, id string) (*Assessment, error) {
+	query := `
+		SELECT id, template_id, employee_id, reviewer_id, status,
+			completed_at, created_at, updated_at, version
+		FROM assessments
+		WHERE id = $1
+	`
+
+	var assessment Assessment
+	var completedAt sql.NullTime
+
+	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
+		&assessment.ID, &assessment.TemplateID, &assessment.EmployeeID, &assessment.ReviewerID,
+		&assessment.Status, &completedAt, &assessment.CreatedAt, &assessment.UpdatedAt, &assessment.Version,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: assessment %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get assessment: %w", err)
+	}
+
+	if completedAt.Valid {
+		assessment.CompletedAt = completedAt.Time
+	}
+
+	return &assessment, nil
+}
+
+// Update updates an assessment
+func (r *PostgresAssessmentRepository) Update(ctx context.Context, assessment *Assessment) error {
+	query := `
+		UPDATE assessments
+		SET template_id = $1, employee_id = $2, reviewer_id = $3, status = $4,
+			completed_at = $5, updated_at = NOW(),
+			version = version + 1
+		WHERE id = $6 AND version = $7
+		RETURNING updated_at, version
+	`
+
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		assessment.TemplateID, assessment.EmployeeID, assessment.ReviewerID, assessment.Status,
+		nullTime(assessment.CompletedAt), assessment.ID, assessment.Version,
+	).Scan(&assessment.UpdatedAt, &assessment.Version)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "assessments", "assessment", assessment.ID, assessment.Version)
+		}
+		return fmt.Errorf("failed to update assessment: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Delete deletes an assessment
+func (r *PostgresAssessmentRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM assessments WHERE id = $1`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete assessment: %w", translatePgError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: assessment %s", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// List lists assessments with optional filters
+func (r *PostgresAssessmentRepository) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*Assessment, int64, error) {
+	// Base query
+	query := `
+		SELECT id, template_id, employee_id, reviewer_id, status,
+			completed_at, created_at, updated_at, version
+		FROM assessments
+	`
+
+	// Where clause and parameters
+	where := ""
+	params := []interface{}{}
+	paramIndex := 1
+
+	if len(filters) > 0 {
+		where = " WHERE "
+		for key, value := range filters {
+			if paramIndex > 1 {
+				where += " AND "
+			}
+			where += fmt.Sprintf("%s = $%d", key, paramIndex)
+			params = append(params, value)
+			paramIndex++
+		}
+	}
+
+	// Add pagination
+	query += where + fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", paramIndex, paramIndex+1)
+	params = append(params, limit, offset)
+
+	// Count query
+	countQuery := "SELECT COUNT(*) FROM assessments" + where
+
+	// Execute count query
+	var total int64
+	err := r.factory.getQueryer().QueryRow(ctx, countQuery, params[:len(params)-2]...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count assessments: %w", err)
+	}
+
+	// Execute main query
+	rows, err := r.factory.getQueryer().Query(ctx, query, params...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list assessments: %w", err)
+	}
+	defer rows.Close()
+
+	assessments := []*Assessment{}
+	for rows.Next() {
+		var assessment Assessment
+		var completedAt sql.NullTime
+
+		err := rows.Scan(
+			&assessment.ID, &assessment.TemplateID, &assessment.EmployeeID, &assessment.ReviewerID,
+			&assessment.Status, &completedAt, &assessment.CreatedAt, &assessment.UpdatedAt, &assessment.Version,
+		)
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan assessment: %w", err)
+		}
+
+		if completedAt.Valid {
+			assessment.CompletedAt = completedAt.Time
+		}
+
+		assessments = append(assessments, &assessment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating assessment rows: %w", err)
+	}
+
+	return assessments, total, nil
+}
+
+// GetTemplateByID retrieves an assessment template by ID
+func (r *PostgresAssessmentRepository) GetTemplateByID(ctx context.Context, id string) (*AssessmentTemplate, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), version, active, created_at, updated_at
+		FROM assessment_templates
+		WHERE id = $1
+	`
+
+	var template AssessmentTemplate
+	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
+		&template.ID, &template.Name, &template.Description, &template.Version,
+		&template.Active, &template.CreatedAt, &template.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: assessment template %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get assessment template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// CountOpenByStatus counts assessments that are neither completed nor
+// cancelled, by status
+func (r *PostgresAssessmentRepository) CountOpenByStatus(ctx context.Context) (map[string]int64, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM assessments
+		WHERE status NOT IN ('completed', 'cancelled')
+		GROUP BY status
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open assessments: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan assessment count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assessment count rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// PostgresGoalRepository implements GoalRepository for PostgreSQL
+type PostgresGoalRepository struct {
+	factory *PostgresFactory
+}
+
+// Create creates a new goal
+func (r *PostgresGoalRepository) Create(ctx context.Context, goal *Goal) (string, error) {
+	query := `
+		INSERT INTO goals (
+			employee_id, title, description, time_frame, type, status, due_date
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id string
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		goal.EmployeeID, goal.Title, goal.Description, goal.TimeFrame, goal.Type, goal.Status,
+		nullTime(goal.DueDate),
+	).Scan(&id)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create goal: %w", translatePgError(err))
+	}
+
+	return id, nil
+}
+
+// GetByID retrieves a goal by ID
+func (r *PostgresGoalRepository) GetByID(ctx context.Context, id string) (*Goal, error) {
+	query := `
+		SELECT id, employee_id, title, COALESCE(description, ''), time_frame, type, status,
+			due_date, created_at, updated_at, version
+		FROM goals
+		WHERE id = $1
+	`
+
+	var goal Goal
+	var dueDate sql.NullTime
+	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
+		&goal.ID, &goal.EmployeeID, &goal.Title, &goal.Description, &goal.TimeFrame,
+		&goal.Type, &goal.Status, &dueDate, &goal.CreatedAt, &goal.UpdatedAt, &goal.Version,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: goal %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get goal: %w", err)
+	}
+	goal.DueDate = dueDate.Time
+
+	return &goal, nil
+}
+
+// Update updates a goal
+func (r *PostgresGoalRepository) Update(ctx context.Context, goal *Goal) error {
+	query := `
+		UPDATE goals
+		SET title = $1, description = $2, time_frame = $3, type = $4, status = $5,
+			due_date = $8,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = $6 AND version = $7
+		RETURNING updated_at, version
+	`
+
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		goal.Title, goal.Description, goal.TimeFrame, goal.Type, goal.Status, goal.ID, goal.Version,
+		nullTime(goal.DueDate),
+	).Scan(&goal.UpdatedAt, &goal.Version)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "goals", "goal", goal.ID, goal.Version)
+		}
+		return fmt.Errorf("failed to update goal: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Delete deletes a goal together with its check-ins
+func (r *PostgresGoalRepository) Delete(ctx context.Context, id string) error {
+	// Check-ins reference the goal, so they are removed in the same statement
+	query := `
+		WITH deleted_checkins AS (
+			DELETE FROM goal_checkins WHERE goal_id = $1
+		)
+		DELETE FROM goals WHERE id = $1
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", translatePgError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: goal %s", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// GetByEmployee retrieves goals by employee ID
+func (r *PostgresGoalRepository) GetByEmployee(ctx context.Context, employeeID string) ([]*Goal, error) {
+	query := `
+		SELECT id, employee_id, title, COALESCE(description, ''), time_frame, type, status,
+			due_date, created_at, updated_at, version
+		FROM goals
+		WHERE employee_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, query, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals by employee: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []*Goal{}
+	for rows.Next() {
+		var goal Goal
+		var dueDate sql.NullTime
+		err := rows.Scan(
+			&goal.ID, &goal.EmployeeID, &goal.Title, &goal.Description, &goal.TimeFrame,
+			&goal.Type, &goal.Status, &dueDate, &goal.CreatedAt, &goal.UpdatedAt, &goal.Version,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goal.DueDate = dueDate.Time
+
+		goals = append(goals, &goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal rows: %w", err)
+	}
+
+	return goals, nil
+}
+
+// AddCheckIn records a progress check-in on a goal, filling in its creation time
+func (r *PostgresGoalRepository) AddCheckIn(ctx context.Context, checkIn *GoalCheckIn) (string, error) {
+	query := `
+		INSERT INTO goal_checkins (
+			goal_id, note, progress
+		) VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	var id string
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		checkIn.GoalID, checkIn.Note, checkIn.Progress,
+	).Scan(&id, &checkIn.CreatedAt)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create goal check-in: %w", translatePgError(err))
+	}
+
+	return id, nil
+}
+
+// ListCheckIns lists check-ins for a goal, newest first
+func (r *PostgresGoalRepository) ListCheckIns(ctx context.Context, goalID string) ([]*GoalCheckIn, error) {
+	query := `
+		SELECT id, goal_id, COALESCE(note, ''), progress, created_at
+		FROM goal_checkins
+		WHERE goal_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, query, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goal check-ins: %w", err)
+	}
+	defer rows.Close()
+
+	checkIns := []*GoalCheckIn{}
+	for rows.Next() {
+		var checkIn GoalCheckIn
+		err := rows.Scan(
+			&checkIn.ID, &checkIn.GoalID, &checkIn.Note, &checkIn.Progress, &checkIn.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal check-in: %w", err)
+		}
+
+		checkIns = append(checkIns, &checkIn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal check-in rows: %w", err)
+	}
+
+	return checkIns, nil
+}
+
+// CountOverdue counts active goals whose due date is before the given day
+func (r *PostgresGoalRepository) CountOverdue(ctx context.Context, asOf time.Time) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM goals
+		WHERE status = 'active' AND due_date < $1::date
+	`
+
+	var count int64
+	if err := r.factory.getQueryer().QueryRow(ctx, query, asOf.Format(time.DateOnly)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count overdue goals: %w", err)
+	}
+
+	return count, nil
+}
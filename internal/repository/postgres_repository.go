@@ -5,10 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgconn"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Define queryer interface for common transaction and pool methods
@@ -20,17 +22,36 @@ type queryer interface {
 
 // PostgresFactory implements the RepositoryFactory interface for PostgreSQL
 type PostgresFactory struct {
-	pool *pgxpool.Pool
-	tx   pgx.Tx
+	pool    *pgxpool.Pool
+	replica *ReplicaPool
+	tx      pgx.Tx
+	cipher  FieldCipher
 }
 
 // NewPostgresFactory creates a new PostgreSQL repository factory
 func NewPostgresFactory(pool *pgxpool.Pool) *PostgresFactory {
 	return &PostgresFactory{
-		pool: pool,
+		pool:   pool,
+		cipher: plaintextCipher{},
 	}
 }
 
+// SetFieldCipher sets the cipher used for encrypted columns
+func (f *PostgresFactory) SetFieldCipher(cipher FieldCipher) {
+	f.cipher = cipher
+}
+
+// SetReadReplica routes read-only statements outside transactions to the
+// replica while it is healthy
+func (f *PostgresFactory) SetReadReplica(replica *ReplicaPool) {
+	f.replica = replica
+}
+
+// Tenants returns a TenantRepository
+func (f *PostgresFactory) Tenants() TenantRepository {
+	return &PostgresTenantRepository{factory: f}
+}
+
 // Employees returns an EmployeeRepository
 func (f *PostgresFactory) Employees() EmployeeRepository {
 	return &PostgresEmployeeRepository{factory: f}
@@ -51,20 +72,52 @@ func (f *PostgresFactory) Sites() SiteRepository {
 	return &PostgresSiteRepository{factory: f}
 }
 
-// WithTransaction starts a new transaction and returns a RepositoryFactory that uses it
+// Assessments returns an AssessmentRepository
+func (f *PostgresFactory) Assessments() AssessmentRepository {
+	return &PostgresAssessmentRepository{factory: f}
+}
+
+// Goals returns a GoalRepository
+func (f *PostgresFactory) Goals() GoalRepository {
+	return &PostgresGoalRepository{factory: f}
+}
+
+// AuditLogs returns an AuditLogRepository
+func (f *PostgresFactory) AuditLogs() AuditLogRepository {
+	return &PostgresAuditLogRepository{factory: f}
+}
+
+// ChangeRequests returns an EmployeeChangeRequestRepository
+func (f *PostgresFactory) ChangeRequests() EmployeeChangeRequestRepository {
+	return &PostgresEmployeeChangeRequestRepository{factory: f}
+}
+
+// WithTransaction starts a new transaction, scoped to the tenant and actor
+// carried by the context, and returns a RepositoryFactory that uses it
 func (f *PostgresFactory) WithTransaction(ctx context.Context) (RepositoryFactory, error) {
 	if f.tx != nil {
 		return nil, errors.New("transaction already started")
 	}
 
+	// Transactions always run on the primary, and later reads in the same
+	// request follow them there
+	markWrite(ctx)
 	tx, err := f.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	if hasSession(ctx) {
+		if err := setSession(ctx, tx); err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, err
+		}
+	}
 
 	return &PostgresFactory{
-		pool: f.pool,
-		tx:   tx,
+		pool:    f.pool,
+		replica: f.replica,
+		tx:      tx,
+		cipher:  f.cipher,
 	}, nil
 }
 
@@ -98,12 +151,31 @@ func (f *PostgresFactory) Rollback() error {
 	return nil
 }
 
-// getQueryer returns the appropriate queryer (transaction or pool)
+// getQueryer returns the appropriate queryer (transaction or scoped pool)
 func (f *PostgresFactory) getQueryer() queryer {
 	if f.tx != nil {
 		return f.tx
 	}
-	return f.pool
+	return scopedQueryer{pool: f.pool, replica: f.replica}
+}
+
+// staleUpdateError explains why an optimistic update matched no rows: either
+// the record does not exist or its version has moved on
+func (f *PostgresFactory) staleUpdateError(ctx context.Context, table, kind, id string, expected int) error {
+	var current int
+	err := f.getQueryer().QueryRow(ctx, fmt.Sprintf("SELECT version FROM %s WHERE id = $1", table), id).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s %s", ErrNotFound, kind, id)
+		}
+		return fmt.Errorf("failed to check %s version: %w", kind, err)
+	}
+	return fmt.Errorf("%w: %s %s is at version %d, not %d", ErrVersionConflict, kind, id, current, expected)
+}
+
+// nullTime converts a zero time into a SQL NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
 }
 
 // PostgresEmployeeRepository implements EmployeeRepository for PostgreSQL
@@ -117,21 +189,28 @@ func (r *PostgresEmployeeRepository) Create(ctx context.Context, employee *Emplo
 		INSERT INTO employees (
 			first_name, middle_name, last_name, display_name, email, 
 			address, position_id, department_id, site_id, manager_id, 
-			employment_type, start_date, end_date, status, profile_picture_url
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			employment_type, start_date, end_date, status, profile_picture_url,
+			address_bidx
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')::uuid, $11, $12, $13, $14, $15, NULLIF($16, ''))
 		RETURNING id
 	`
 
+	address, err := r.factory.cipher.Encrypt(fieldEmployeeAddress, employee.Address)
+	if err != nil {
+		return "", fmt.Errorf("failed to create employee: %w", err)
+	}
+
 	var id string
-	err := r.factory.getQueryer().QueryRow(ctx, query,
+	err = r.factory.getQueryer().QueryRow(ctx, query,
 		employee.FirstName, employee.MiddleName, employee.LastName, employee.DisplayName,
-		employee.Email, employee.Address, employee.PositionID, employee.DepartmentID,
+		employee.Email, address, employee.PositionID, employee.DepartmentID,
 		employee.SiteID, employee.ManagerID, employee.EmploymentType, employee.StartDate,
-		employee.EndDate, employee.Status, employee.ProfilePicture,
+		nullTime(employee.EndDate), employee.Status, employee.ProfilePicture,
+		r.factory.cipher.BlindIndex(fieldEmployeeAddress, employee.Address),
 	).Scan(&id)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to create employee: %w", err)
+		return "", fmt.Errorf("failed to create employee: %w", translatePgError(err))
 	}
 
 	return id, nil
@@ -139,29 +218,39 @@ func (r *PostgresEmployeeRepository) Create(ctx context.Context, employee *Emplo
 
 // GetByID retrieves an employee by ID
 func (r *PostgresEmployeeRepository) GetByID(ctx context.Context, id string) (*Employee, error) {
+	return r.getByID(ctx, id, false)
+}
+
+// GetByIDIncludingDeleted retrieves an employee by ID even if they were soft deleted
+func (r *PostgresEmployeeRepository) GetByIDIncludingDeleted(ctx context.Context, id string) (*Employee, error) {
+	return r.getByID(ctx, id, true)
+}
+
+// getByID retrieves an employee, optionally including soft-deleted ones
+func (r *PostgresEmployeeRepository) getByID(ctx context.Context, id string, includeDeleted bool) (*Employee, error) {
 	query := `
-		SELECT id, first_name, middle_name, last_name, display_name, email, 
-			address, position_id, department_id, site_id, manager_id, 
-			employment_type, start_date, end_date, status, profile_picture_url,
-			created_at, updated_at
+		SELECT id, first_name, COALESCE(middle_name, ''), last_name, display_name, email,
+			COALESCE(address, ''), position_id, department_id, site_id, COALESCE(manager_id::text, ''),
+			employment_type, start_date, end_date, status, COALESCE(profile_picture_url, ''),
+			created_at, updated_at, version
 		FROM employees
-		WHERE id = $1
+		WHERE id = $1 AND (deleted_at IS NULL OR $2)
 	`
 
 	var employee Employee
 	var endDate sql.NullTime
 
-	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
+	err := r.factory.getQueryer().QueryRow(ctx, query, id, includeDeleted).Scan(
 		&employee.ID, &employee.FirstName, &employee.MiddleName, &employee.LastName,
 		&employee.DisplayName, &employee.Email, &employee.Address, &employee.PositionID,
 		&employee.DepartmentID, &employee.SiteID, &employee.ManagerID, &employee.EmploymentType,
 		&employee.StartDate, &endDate, &employee.Status, &employee.ProfilePicture,
-		&employee.CreatedAt, &employee.UpdatedAt,
+		&employee.CreatedAt, &employee.UpdatedAt, &employee.Version,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("employee not found: %s", id)
+			return nil, fmt.Errorf("%w: employee %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get employee: %w", err)
 	}
@@ -170,6 +259,10 @@ func (r *PostgresEmployeeRepository) GetByID(ctx context.Context, id string) (*E
 		employee.EndDate = endDate.Time
 	}
 
+	if err := r.decryptFields(&employee); err != nil {
+		return nil, err
+	}
+
 	return &employee, nil
 }
 
@@ -179,71 +272,203 @@ func (r *PostgresEmployeeRepository) Update(ctx context.Context, employee *Emplo
 		UPDATE employees
 		SET first_name = $1, middle_name = $2, last_name = $3, display_name = $4,
 			email = $5, address = $6, position_id = $7, department_id = $8,
-			site_id = $9, manager_id = $10, employment_type = $11, start_date = $12,
-			end_date = $13, status = $14, profile_picture_url = $15, updated_at = NOW()
-		WHERE id = $16
+			site_id = $9, manager_id = NULLIF($10, '')::uuid, employment_type = $11, start_date = $12,
+			end_date = $13, status = $14, profile_picture_url = $15, address_bidx = NULLIF($18, ''),
+			updated_at = NOW(), version = version + 1
+		WHERE id = $16 AND version = $17 AND deleted_at IS NULL
+		RETURNING updated_at, version
 	`
 
-	result, err := r.factory.getQueryer().Exec(ctx, query,
+	address, err := r.factory.cipher.Encrypt(fieldEmployeeAddress, employee.Address)
+	if err != nil {
+		return fmt.Errorf("failed to update employee: %w", err)
+	}
+
+	err = r.factory.getQueryer().QueryRow(ctx, query,
 		employee.FirstName, employee.MiddleName, employee.LastName, employee.DisplayName,
-		employee.Email, employee.Address, employee.PositionID, employee.DepartmentID,
+		employee.Email, address, employee.PositionID, employee.DepartmentID,
 		employee.SiteID, employee.ManagerID, employee.EmploymentType, employee.StartDate,
-		employee.EndDate, employee.Status, employee.ProfilePicture, employee.ID,
-	)
+		nullTime(employee.EndDate), employee.Status, employee.ProfilePicture, employee.ID, employee.Version,
+		r.factory.cipher.BlindIndex(fieldEmployeeAddress, employee.Address),
+	).Scan(&employee.UpdatedAt, &employee.Version)
 
 	if err != nil {
-		return fmt.Errorf("failed to update employee: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "employees", "employee", employee.ID, employee.Version)
+		}
+		return fmt.Errorf("failed to update employee: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Delete soft deletes an employee
+func (r *PostgresEmployeeRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE employees
+		SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete employee: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("employee not found: %s", employee.ID)
+		return fmt.Errorf("%w: employee %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
-// Delete deletes an employee
-func (r *PostgresEmployeeRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM employees WHERE id = $1`
+// Restore brings back a soft-deleted employee
+func (r *PostgresEmployeeRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE employees
+		SET deleted_at = NULL, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore employee: %w", translatePgError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: deleted employee %s", ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// Anonymise replaces an employee's personal data with placeholders, keeping
+// the row so that goals, assessments and reporting lines stay intact
+func (r *PostgresEmployeeRepository) Anonymise(ctx context.Context, id string) error {
+	query := `
+		UPDATE employees
+		SET first_name = 'Anonymised', middle_name = NULL, last_name = 'Employee',
+			display_name = 'Anonymised Employee', email = 'anonymised-' || id || '@invalid',
+			address = NULL, address_bidx = NULL, profile_picture_url = NULL, updated_at = NOW(),
+			version = version + 1
+		WHERE id = $1
+	`
 
 	result, err := r.factory.getQueryer().Exec(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete employee: %w", err)
+		return fmt.Errorf("failed to anonymise employee: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("employee not found: %s", id)
+		return fmt.Errorf("%w: employee %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
+// Reencrypt rewrites the encrypted columns of every employee with the current
+// cipher, encrypting values stored before encryption was enabled and moving
+// values wrapped by older keys onto the active key
+func (r *PostgresEmployeeRepository) Reencrypt(ctx context.Context) (int64, error) {
+	rows, err := r.factory.getQueryer().Query(ctx, `SELECT id, address FROM employees WHERE address <> ''`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list employee addresses: %w", err)
+	}
+
+	addresses := map[string]string{}
+	for rows.Next() {
+		var id, address string
+		if err := rows.Scan(&id, &address); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan employee address: %w", err)
+		}
+		addresses[id] = address
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating employee address rows: %w", err)
+	}
+
+	// The version is left alone: the values do not change, only their encryption
+	query := `UPDATE employees SET address = $1, address_bidx = NULLIF($2, '') WHERE id = $3`
+
+	var count int64
+	for id, stored := range addresses {
+		plaintext, err := r.factory.cipher.Decrypt(fieldEmployeeAddress, stored)
+		if err != nil {
+			return count, fmt.Errorf("failed to decrypt employee %s: %w", id, err)
+		}
+		address, err := r.factory.cipher.Encrypt(fieldEmployeeAddress, plaintext)
+		if err != nil {
+			return count, fmt.Errorf("failed to encrypt employee %s: %w", id, err)
+		}
+
+		_, err = r.factory.getQueryer().Exec(ctx, query,
+			address, r.factory.cipher.BlindIndex(fieldEmployeeAddress, plaintext), id)
+		if err != nil {
+			return count, fmt.Errorf("failed to reencrypt employee %s: %w", id, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Purge permanently removes employees that were deleted before the cutoff
+func (r *PostgresEmployeeRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	// Employees still referenced as a manager, department lead or reviewer of
+	// someone else are kept until those references are gone
+	query := `
+		WITH purged AS (
+			SELECT e.id FROM employees e
+			WHERE e.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM employees m WHERE m.manager_id = e.id)
+				AND NOT EXISTS (SELECT 1 FROM departments d WHERE d.lead_id = e.id)
+				AND NOT EXISTS (SELECT 1 FROM assessments a WHERE a.reviewer_id = e.id AND a.employee_id <> e.id)
+		), checkins AS (
+			DELETE FROM goal_checkins
+			WHERE goal_id IN (SELECT id FROM goals WHERE employee_id IN (SELECT id FROM purged))
+		), goals AS (
+			DELETE FROM goals WHERE employee_id IN (SELECT id FROM purged)
+		), assessments AS (
+			DELETE FROM assessments WHERE employee_id IN (SELECT id FROM purged)
+		)
+		DELETE FROM employees WHERE id IN (SELECT id FROM purged)
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge employees: %w", translatePgError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // List lists employees with optional filters
 func (r *PostgresEmployeeRepository) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*Employee, int64, error) {
 	// Base query
 	query := `
-		SELECT id, first_name, middle_name, last_name, display_name, email, 
-			address, position_id, department_id, site_id, manager_id, 
-			employment_type, start_date, end_date, status, profile_picture_url,
-			created_at, updated_at
+		SELECT id, first_name, COALESCE(middle_name, ''), last_name, display_name, email,
+			COALESCE(address, ''), position_id, department_id, site_id, COALESCE(manager_id::text, ''),
+			employment_type, start_date, end_date, status, COALESCE(profile_picture_url, ''),
+			created_at, updated_at, version
 		FROM employees
 	`
 
 	// Where clause and parameters
-	where := ""
+	where := " WHERE deleted_at IS NULL"
 	params := []interface{}{}
 	paramIndex := 1
 
-	if len(filters) > 0 {
-		where = " WHERE "
-		for key, value := range filters {
-			if paramIndex > 1 {
-				where += " AND "
-			}
-			where += fmt.Sprintf("%s = $%d", key, paramIndex)
-			params = append(params, value)
-			paramIndex++
+	for key, value := range filters {
+		// Encrypted columns are matched through their blind index
+		if key == "address" {
+			key = "address_bidx"
+			value = r.factory.cipher.BlindIndex(fieldEmployeeAddress, fmt.Sprint(value))
 		}
+		where += fmt.Sprintf(" AND %s = $%d", key, paramIndex)
+		params = append(params, value)
+		paramIndex++
 	}
 
 	// Add pagination
@@ -277,7 +502,7 @@ func (r *PostgresEmployeeRepository) List(ctx context.Context, filters map[strin
 			&employee.DisplayName, &employee.Email, &employee.Address, &employee.PositionID,
 			&employee.DepartmentID, &employee.SiteID, &employee.ManagerID, &employee.EmploymentType,
 			&employee.StartDate, &endDate, &employee.Status, &employee.ProfilePicture,
-			&employee.CreatedAt, &employee.UpdatedAt,
+			&employee.CreatedAt, &employee.UpdatedAt, &employee.Version,
 		)
 
 		if err != nil {
@@ -288,6 +513,10 @@ func (r *PostgresEmployeeRepository) List(ctx context.Context, filters map[strin
 			employee.EndDate = endDate.Time
 		}
 
+		if err := r.decryptFields(&employee); err != nil {
+			return nil, 0, err
+		}
+
 		employees = append(employees, &employee)
 	}
 
@@ -301,12 +530,12 @@ func (r *PostgresEmployeeRepository) List(ctx context.Context, filters map[strin
 // GetByManager retrieves employees by manager ID
 func (r *PostgresEmployeeRepository) GetByManager(ctx context.Context, managerID string) ([]*Employee, error) {
 	query := `
-		SELECT id, first_name, middle_name, last_name, display_name, email, 
-			address, position_id, department_id, site_id, manager_id, 
-			employment_type, start_date, end_date, status, profile_picture_url,
-			created_at, updated_at
+		SELECT id, first_name, COALESCE(middle_name, ''), last_name, display_name, email,
+			COALESCE(address, ''), position_id, department_id, site_id, COALESCE(manager_id::text, ''),
+			employment_type, start_date, end_date, status, COALESCE(profile_picture_url, ''),
+			created_at, updated_at, version
 		FROM employees
-		WHERE manager_id = $1
+		WHERE manager_id = $1 AND deleted_at IS NULL
 		ORDER BY last_name, first_name
 	`
 
@@ -326,7 +555,7 @@ func (r *PostgresEmployeeRepository) GetByManager(ctx context.Context, managerID
 			&employee.DisplayName, &employee.Email, &employee.Address, &employee.PositionID,
 			&employee.DepartmentID, &employee.SiteID, &employee.ManagerID, &employee.EmploymentType,
 			&employee.StartDate, &endDate, &employee.Status, &employee.ProfilePicture,
-			&employee.CreatedAt, &employee.UpdatedAt,
+			&employee.CreatedAt, &employee.UpdatedAt, &employee.Version,
 		)
 
 		if err != nil {
@@ -337,6 +566,10 @@ func (r *PostgresEmployeeRepository) GetByManager(ctx context.Context, managerID
 			employee.EndDate = endDate.Time
 		}
 
+		if err := r.decryptFields(&employee); err != nil {
+			return nil, err
+		}
+
 		employees = append(employees, &employee)
 	}
 
@@ -350,12 +583,12 @@ func (r *PostgresEmployeeRepository) GetByManager(ctx context.Context, managerID
 // GetByDepartment retrieves employees by department ID
 func (r *PostgresEmployeeRepository) GetByDepartment(ctx context.Context, departmentID string) ([]*Employee, error) {
 	query := `
-		SELECT id, first_name, middle_name, last_name, display_name, email, 
-			address, position_id, department_id, site_id, manager_id, 
-			employment_type, start_date, end_date, status, profile_picture_url,
-			created_at, updated_at
+		SELECT id, first_name, COALESCE(middle_name, ''), last_name, display_name, email,
+			COALESCE(address, ''), position_id, department_id, site_id, COALESCE(manager_id::text, ''),
+			employment_type, start_date, end_date, status, COALESCE(profile_picture_url, ''),
+			created_at, updated_at, version
 		FROM employees
-		WHERE department_id = $1
+		WHERE department_id = $1 AND deleted_at IS NULL
 		ORDER BY last_name, first_name
 	`
 
@@ -375,7 +608,7 @@ func (r *PostgresEmployeeRepository) GetByDepartment(ctx context.Context, depart
 			&employee.DisplayName, &employee.Email, &employee.Address, &employee.PositionID,
 			&employee.DepartmentID, &employee.SiteID, &employee.ManagerID, &employee.EmploymentType,
 			&employee.StartDate, &endDate, &employee.Status, &employee.ProfilePicture,
-			&employee.CreatedAt, &employee.UpdatedAt,
+			&employee.CreatedAt, &employee.UpdatedAt, &employee.Version,
 		)
 
 		if err != nil {
@@ -386,6 +619,10 @@ func (r *PostgresEmployeeRepository) GetByDepartment(ctx context.Context, depart
 			employee.EndDate = endDate.Time
 		}
 
+		if err := r.decryptFields(&employee); err != nil {
+			return nil, err
+		}
+
 		employees = append(employees, &employee)
 	}
 
@@ -396,6 +633,184 @@ func (r *PostgresEmployeeRepository) GetByDepartment(ctx context.Context, depart
 	return employees, nil
 }
 
+// CountActive counts employees with active status that have not been deleted
+func (r *PostgresEmployeeRepository) CountActive(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM employees
+		WHERE status = 'active' AND deleted_at IS NULL
+	`
+
+	var count int64
+	if err := r.factory.getQueryer().QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count active employees: %w", err)
+	}
+
+	return count, nil
+}
+
+// Search finds employees whose names, email, position, department or site
+// contain every query word as a prefix, or whose name or organisation is
+// similar to the query to tolerate typos. Results are ranked by full-text
+// relevance plus trigram similarity, and only include employees the acting
+// user is allowed to see.
+func (r *PostgresEmployeeRepository) Search(ctx context.Context, query string, limit int) ([]*EmployeeSearchResult, error) {
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return []*EmployeeSearchResult{}, nil
+	}
+
+	// The candidates are the employees that match a term, or are similar to
+	// the query, in one of the document's sources, found through the indexes
+	// of each. The documents of the candidates are then built and checked.
+	sqlQuery := `
+		WITH candidates AS (
+			SELECT id FROM employees WHERE search_vector @@ to_tsquery('simple', $5)
+			UNION
+			SELECT id FROM employees WHERE $2 <% display_name
+			UNION
+			SELECT id FROM employees WHERE $2 <% split_part(email, '@', 1)
+			UNION
+			SELECT e.id FROM employees e
+			JOIN positions p ON p.id = e.position_id
+			WHERE to_tsvector('simple', p.title) @@ to_tsquery('simple', $5) OR $2 <% p.title
+			UNION
+			SELECT e.id FROM employees e
+			JOIN departments d ON d.id = e.department_id
+			WHERE to_tsvector('simple', d.name) @@ to_tsquery('simple', $5) OR $2 <% d.name
+			UNION
+			SELECT e.id FROM employees e
+			JOIN sites s ON s.id = e.site_id
+			WHERE to_tsvector('simple', concat_ws(' ', s.name, s.city)) @@ to_tsquery('simple', $5) OR $2 <% s.name
+		)
+		SELECT e.id, e.display_name, e.email, COALESCE(p.title, ''), COALESCE(d.name, ''),
+			COALESCE(s.name, ''), COALESCE(e.profile_picture_url, ''),
+			ts_rank(doc.vector, q.terms) + doc.similarity AS rank
+		FROM employees e
+		JOIN candidates c ON c.id = e.id
+		LEFT JOIN positions p ON p.id = e.position_id AND p.deleted_at IS NULL
+		LEFT JOIN departments d ON d.id = e.department_id AND d.deleted_at IS NULL
+		LEFT JOIN sites s ON s.id = e.site_id AND s.deleted_at IS NULL
+		CROSS JOIN to_tsquery('simple', $1) AS q(terms)
+		CROSS JOIN LATERAL (
+			SELECT
+				e.search_vector
+				|| setweight(to_tsvector('simple', COALESCE(p.title, '')), 'B')
+				|| setweight(to_tsvector('simple', concat_ws(' ', d.name, s.name, s.city)), 'C') AS vector,
+				GREATEST(
+					word_similarity($2, e.display_name),
+					word_similarity($2, split_part(e.email, '@', 1)),
+					word_similarity($2, COALESCE(p.title, '')),
+					word_similarity($2, COALESCE(d.name, '')),
+					word_similarity($2, COALESCE(s.name, ''))
+				) AS similarity
+		) doc
+		WHERE e.deleted_at IS NULL
+			AND (doc.vector @@ q.terms OR doc.similarity >= $3)
+		ORDER BY rank DESC, e.display_name
+		LIMIT $4
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, sqlQuery,
+		prefixQuery(terms), strings.Join(terms, " "), searchSimilarityThreshold, limit, anyPrefixQuery(terms))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search employees: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*EmployeeSearchResult{}
+	for rows.Next() {
+		var result EmployeeSearchResult
+		err := rows.Scan(
+			&result.ID, &result.DisplayName, &result.Email, &result.PositionTitle,
+			&result.DepartmentName, &result.SiteName, &result.ProfilePicture, &result.Rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan employee search result: %w", err)
+		}
+		results = append(results, &result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating employee search rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// UpdateProfile updates the display name, address and profile picture of an
+// employee, leaving the fields only HR maintains untouched
+func (r *PostgresEmployeeRepository) UpdateProfile(ctx context.Context, employee *Employee) error {
+	query := `
+		UPDATE employees
+		SET display_name = $1, address = $2, address_bidx = NULLIF($3, ''), profile_picture_url = NULLIF($4, ''),
+			updated_at = NOW(), version = version + 1
+		WHERE id = $5 AND version = $6 AND deleted_at IS NULL
+		RETURNING updated_at, version
+	`
+
+	address, err := r.factory.cipher.Encrypt(fieldEmployeeAddress, employee.Address)
+	if err != nil {
+		return fmt.Errorf("failed to update employee profile: %w", err)
+	}
+
+	err = r.factory.getQueryer().QueryRow(ctx, query,
+		employee.DisplayName, address, r.factory.cipher.BlindIndex(fieldEmployeeAddress, employee.Address),
+		employee.ProfilePicture, employee.ID, employee.Version,
+	).Scan(&employee.UpdatedAt, &employee.Version)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "employees", "employee", employee.ID, employee.Version)
+		}
+		return fmt.Errorf("failed to update employee profile: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// ManagerChain lists the managers above an employee, nearest first. It reads
+// through employee_manager_chain, since employees cannot see their managers'
+// records, which only returns names and positions.
+func (r *PostgresEmployeeRepository) ManagerChain(ctx context.Context, id string) ([]*EmployeeSummary, error) {
+	query := `
+		SELECT id, display_name, email, COALESCE(position_title, '')
+		FROM employee_manager_chain($1)
+		ORDER BY depth
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manager chain: %w", err)
+	}
+	defer rows.Close()
+
+	managers := []*EmployeeSummary{}
+	for rows.Next() {
+		var manager EmployeeSummary
+		if err := rows.Scan(&manager.ID, &manager.DisplayName, &manager.Email, &manager.PositionTitle); err != nil {
+			return nil, fmt.Errorf("failed to scan manager: %w", err)
+		}
+		managers = append(managers, &manager)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating manager rows: %w", err)
+	}
+
+	return managers, nil
+}
+
+// decryptFields decrypts the encrypted columns of a scanned employee
+func (r *PostgresEmployeeRepository) decryptFields(employee *Employee) error {
+	address, err := r.factory.cipher.Decrypt(fieldEmployeeAddress, employee.Address)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt employee %s: %w", employee.ID, err)
+	}
+	employee.Address = address
+	return nil
+}
+
 // PostgresPositionRepository implements PositionRepository for PostgreSQL
 type PostgresPositionRepository struct {
 	factory *PostgresFactory
@@ -416,7 +831,7 @@ func (r *PostgresPositionRepository) Create(ctx context.Context, position *Posit
 	).Scan(&id)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to create position: %w", err)
+		return "", fmt.Errorf("failed to create position: %w", translatePgError(err))
 	}
 
 	return id, nil
@@ -425,20 +840,20 @@ func (r *PostgresPositionRepository) Create(ctx context.Context, position *Posit
 // GetByID retrieves a position by ID
 func (r *PostgresPositionRepository) GetByID(ctx context.Context, id string) (*Position, error) {
 	query := `
-		SELECT id, title, description, requirements, created_at, updated_at
+		SELECT id, title, COALESCE(description, ''), COALESCE(requirements, ''), created_at, updated_at, version
 		FROM positions
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var position Position
 	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
 		&position.ID, &position.Title, &position.Description, &position.Requirements,
-		&position.CreatedAt, &position.UpdatedAt,
+		&position.CreatedAt, &position.UpdatedAt, &position.Version,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("position not found: %s", id)
+			return nil, fmt.Errorf("%w: position %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get position: %w", err)
 	}
@@ -450,45 +865,86 @@ func (r *PostgresPositionRepository) GetByID(ctx context.Context, id string) (*P
 func (r *PostgresPositionRepository) Update(ctx context.Context, position *Position) error {
 	query := `
 		UPDATE positions
-		SET title = $1, description = $2, requirements = $3, updated_at = NOW()
-		WHERE id = $4
+		SET title = $1, description = $2, requirements = $3, updated_at = NOW(),
+			version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+		RETURNING updated_at, version
 	`
 
-	result, err := r.factory.getQueryer().Exec(ctx, query,
-		position.Title, position.Description, position.Requirements, position.ID,
-	)
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		position.Title, position.Description, position.Requirements, position.ID, position.Version,
+	).Scan(&position.UpdatedAt, &position.Version)
 
 	if err != nil {
-		return fmt.Errorf("failed to update position: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "positions", "position", position.ID, position.Version)
+		}
+		return fmt.Errorf("failed to update position: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Delete soft deletes a position
+func (r *PostgresPositionRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE positions
+		SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete position: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("position not found: %s", position.ID)
+		return fmt.Errorf("%w: position %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
-// Delete deletes a position
-func (r *PostgresPositionRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM positions WHERE id = $1`
+// Restore brings back a soft-deleted position
+func (r *PostgresPositionRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE positions
+		SET deleted_at = NULL, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
 
 	result, err := r.factory.getQueryer().Exec(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete position: %w", err)
+		return fmt.Errorf("failed to restore position: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("position not found: %s", id)
+		return fmt.Errorf("%w: deleted position %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
+// Purge permanently removes positions that were deleted before the cutoff
+func (r *PostgresPositionRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM positions p
+		WHERE p.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM employees e WHERE e.position_id = p.id)
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge positions: %w", translatePgError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // List lists positions
 func (r *PostgresPositionRepository) List(ctx context.Context, limit, offset int) ([]*Position, int64, error) {
 	// Count query
-	countQuery := "SELECT COUNT(*) FROM positions"
+	countQuery := "SELECT COUNT(*) FROM positions WHERE deleted_at IS NULL"
 	var total int64
 	err := r.factory.getQueryer().QueryRow(ctx, countQuery).Scan(&total)
 	if err != nil {
@@ -497,8 +953,9 @@ func (r *PostgresPositionRepository) List(ctx context.Context, limit, offset int
 
 	// Main query
 	query := `
-		SELECT id, title, description, requirements, created_at, updated_at
+		SELECT id, title, COALESCE(description, ''), COALESCE(requirements, ''), created_at, updated_at, version
 		FROM positions
+		WHERE deleted_at IS NULL
 		ORDER BY title
 		LIMIT $1 OFFSET $2
 	`
@@ -514,7 +971,7 @@ func (r *PostgresPositionRepository) List(ctx context.Context, limit, offset int
 		var position Position
 		err := rows.Scan(
 			&position.ID, &position.Title, &position.Description, &position.Requirements,
-			&position.CreatedAt, &position.UpdatedAt,
+			&position.CreatedAt, &position.UpdatedAt, &position.Version,
 		)
 
 		if err != nil {
@@ -541,7 +998,7 @@ func (r *PostgresDepartmentRepository) Create(ctx context.Context, department *D
 	query := `
 		INSERT INTO departments (
 			name, description, lead_id
-		) VALUES ($1, $2, $3)
+		) VALUES ($1, $2, NULLIF($3, '')::uuid)
 		RETURNING id
 	`
 
@@ -551,7 +1008,7 @@ func (r *PostgresDepartmentRepository) Create(ctx context.Context, department *D
 	).Scan(&id)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to create department: %w", err)
+		return "", fmt.Errorf("failed to create department: %w", translatePgError(err))
 	}
 
 	return id, nil
@@ -560,20 +1017,20 @@ func (r *PostgresDepartmentRepository) Create(ctx context.Context, department *D
 // GetByID retrieves a department by ID
 func (r *PostgresDepartmentRepository) GetByID(ctx context.Context, id string) (*Department, error) {
 	query := `
-		SELECT id, name, description, lead_id, created_at, updated_at
+		SELECT id, name, COALESCE(description, ''), COALESCE(lead_id::text, ''), created_at, updated_at, version
 		FROM departments
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var department Department
 	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
 		&department.ID, &department.Name, &department.Description, &department.LeadID,
-		&department.CreatedAt, &department.UpdatedAt,
+		&department.CreatedAt, &department.UpdatedAt, &department.Version,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("department not found: %s", id)
+			return nil, fmt.Errorf("%w: department %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get department: %w", err)
 	}
@@ -585,45 +1042,86 @@ func (r *PostgresDepartmentRepository) GetByID(ctx context.Context, id string) (
 func (r *PostgresDepartmentRepository) Update(ctx context.Context, department *Department) error {
 	query := `
 		UPDATE departments
-		SET name = $1, description = $2, lead_id = $3, updated_at = NOW()
-		WHERE id = $4
+		SET name = $1, description = $2, lead_id = NULLIF($3, '')::uuid, updated_at = NOW(),
+			version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+		RETURNING updated_at, version
 	`
 
-	result, err := r.factory.getQueryer().Exec(ctx, query,
-		department.Name, department.Description, department.LeadID, department.ID,
-	)
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		department.Name, department.Description, department.LeadID, department.ID, department.Version,
+	).Scan(&department.UpdatedAt, &department.Version)
 
 	if err != nil {
-		return fmt.Errorf("failed to update department: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "departments", "department", department.ID, department.Version)
+		}
+		return fmt.Errorf("failed to update department: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Delete soft deletes a department
+func (r *PostgresDepartmentRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE departments
+		SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete department: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("department not found: %s", department.ID)
+		return fmt.Errorf("%w: department %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
-// Delete deletes a department
-func (r *PostgresDepartmentRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM departments WHERE id = $1`
+// Restore brings back a soft-deleted department
+func (r *PostgresDepartmentRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE departments
+		SET deleted_at = NULL, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
 
 	result, err := r.factory.getQueryer().Exec(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete department: %w", err)
+		return fmt.Errorf("failed to restore department: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("department not found: %s", id)
+		return fmt.Errorf("%w: deleted department %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
+// Purge permanently removes departments that were deleted before the cutoff
+func (r *PostgresDepartmentRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM departments d
+		WHERE d.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM employees e WHERE e.department_id = d.id)
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge departments: %w", translatePgError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // List lists departments
 func (r *PostgresDepartmentRepository) List(ctx context.Context, limit, offset int) ([]*Department, int64, error) {
 	// Count query
-	countQuery := "SELECT COUNT(*) FROM departments"
+	countQuery := "SELECT COUNT(*) FROM departments WHERE deleted_at IS NULL"
 	var total int64
 	err := r.factory.getQueryer().QueryRow(ctx, countQuery).Scan(&total)
 	if err != nil {
@@ -632,8 +1130,9 @@ func (r *PostgresDepartmentRepository) List(ctx context.Context, limit, offset i
 
 	// Main query
 	query := `
-		SELECT id, name, description, lead_id, created_at, updated_at
+		SELECT id, name, COALESCE(description, ''), COALESCE(lead_id::text, ''), created_at, updated_at, version
 		FROM departments
+		WHERE deleted_at IS NULL
 		ORDER BY name
 		LIMIT $1 OFFSET $2
 	`
@@ -649,7 +1148,7 @@ func (r *PostgresDepartmentRepository) List(ctx context.Context, limit, offset i
 		var department Department
 		err := rows.Scan(
 			&department.ID, &department.Name, &department.Description, &department.LeadID,
-			&department.CreatedAt, &department.UpdatedAt,
+			&department.CreatedAt, &department.UpdatedAt, &department.Version,
 		)
 
 		if err != nil {
@@ -686,7 +1185,7 @@ func (r *PostgresSiteRepository) Create(ctx context.Context, site *Site) (string
 	).Scan(&id)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to create site: %w", err)
+		return "", fmt.Errorf("failed to create site: %w", translatePgError(err))
 	}
 
 	return id, nil
@@ -695,20 +1194,20 @@ func (r *PostgresSiteRepository) Create(ctx context.Context, site *Site) (string
 // GetByID retrieves a site by ID
 func (r *PostgresSiteRepository) GetByID(ctx context.Context, id string) (*Site, error) {
 	query := `
-		SELECT id, name, city, address, created_at, updated_at
+		SELECT id, name, city, address, created_at, updated_at, version
 		FROM sites
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var site Site
 	err := r.factory.getQueryer().QueryRow(ctx, query, id).Scan(
 		&site.ID, &site.Name, &site.City, &site.Address,
-		&site.CreatedAt, &site.UpdatedAt,
+		&site.CreatedAt, &site.UpdatedAt, &site.Version,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("site not found: %s", id)
+			return nil, fmt.Errorf("%w: site %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get site: %w", err)
 	}
@@ -720,45 +1219,86 @@ func (r *PostgresSiteRepository) GetByID(ctx context.Context, id string) (*Site,
 func (r *PostgresSiteRepository) Update(ctx context.Context, site *Site) error {
 	query := `
 		UPDATE sites
-		SET name = $1, city = $2, address = $3, updated_at = NOW()
-		WHERE id = $4
+		SET name = $1, city = $2, address = $3, updated_at = NOW(),
+			version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+		RETURNING updated_at, version
 	`
 
-	result, err := r.factory.getQueryer().Exec(ctx, query,
-		site.Name, site.City, site.Address, site.ID,
-	)
+	err := r.factory.getQueryer().QueryRow(ctx, query,
+		site.Name, site.City, site.Address, site.ID, site.Version,
+	).Scan(&site.UpdatedAt, &site.Version)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.factory.staleUpdateError(ctx, "sites", "site", site.ID, site.Version)
+		}
+		return fmt.Errorf("failed to update site: %w", translatePgError(err))
+	}
+
+	return nil
+}
+
+// Delete soft deletes a site
+func (r *PostgresSiteRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE sites
+		SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
 
+	result, err := r.factory.getQueryer().Exec(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to update site: %w", err)
+		return fmt.Errorf("failed to delete site: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("site not found: %s", site.ID)
+		return fmt.Errorf("%w: site %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
-// Delete deletes a site
-func (r *PostgresSiteRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM sites WHERE id = $1`
+// Restore brings back a soft-deleted site
+func (r *PostgresSiteRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE sites
+		SET deleted_at = NULL, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
 
 	result, err := r.factory.getQueryer().Exec(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete site: %w", err)
+		return fmt.Errorf("failed to restore site: %w", translatePgError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("site not found: %s", id)
+		return fmt.Errorf("%w: deleted site %s", ErrNotFound, id)
 	}
 
 	return nil
 }
 
+// Purge permanently removes sites that were deleted before the cutoff
+func (r *PostgresSiteRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM sites s
+		WHERE s.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM employees e WHERE e.site_id = s.id)
+	`
+
+	result, err := r.factory.getQueryer().Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge sites: %w", translatePgError(err))
+	}
+
+	return result.RowsAffected(), nil
+}
+
 // List lists sites
 func (r *PostgresSiteRepository) List(ctx context.Context, limit, offset int) ([]*Site, int64, error) {
 	// Count query
-	countQuery := "SELECT COUNT(*) FROM sites"
+	countQuery := "SELECT COUNT(*) FROM sites WHERE deleted_at IS NULL"
 	var total int64
 	err := r.factory.getQueryer().QueryRow(ctx, countQuery).Scan(&total)
 	if err != nil {
@@ -767,8 +1307,9 @@ func (r *PostgresSiteRepository) List(ctx context.Context, limit, offset int) ([
 
 	// Main query
 	query := `
-		SELECT id, name, city, address, created_at, updated_at
+		SELECT id, name, city, address, created_at, updated_at, version
 		FROM sites
+		WHERE deleted_at IS NULL
 		ORDER BY name
 		LIMIT $1 OFFSET $2
 	`
@@ -784,7 +1325,7 @@ func (r *PostgresSiteRepository) List(ctx context.Context, limit, offset int) ([
 		var site Site
 		err := rows.Scan(
 			&site.ID, &site.Name, &site.City, &site.Address,
-			&site.CreatedAt, &site.UpdatedAt,
+			&site.CreatedAt, &site.UpdatedAt, &site.Version,
 		)
 
 		if err != nil {
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// storageTimeout bounds each storage statement, as the storage interface
+// carries no context
+const storageTimeout = 2 * time.Second
+
+// PostgresStorage is a key-value store with expiry in the http_storage
+// table, satisfying fiber.Storage so rate limits and CSRF tokens are shared
+// by every server instance. Expired entries are ignored when read and
+// deleted in the background.
+type PostgresStorage struct {
+	pool *pgxpool.Pool
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPostgresStorage creates a storage deleting expired entries every
+// interval until it is closed
+func NewPostgresStorage(pool *pgxpool.Pool, gcInterval time.Duration) *PostgresStorage {
+	s := &PostgresStorage{
+		pool: pool,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.gc(gcInterval)
+	return s
+}
+
+// Get returns the value stored under a key, or nil when there is none or it
+// has expired
+func (s *PostgresStorage) Get(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	query := `
+		SELECT value FROM http_storage
+		WHERE key = $1 AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	var value []byte
+	if err := s.pool.QueryRow(ctx, query, key).Scan(&value); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get storage entry: %w", err)
+	}
+	return value, nil
+}
+
+// Set stores a value under a key, expiring after exp unless exp is zero
+func (s *PostgresStorage) Set(key string, value []byte, exp time.Duration) error {
+	if key == "" || len(value) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	var expiresAt *time.Time
+	if exp > 0 {
+		t := time.Now().Add(exp)
+		expiresAt = &t
+	}
+
+	query := `
+		INSERT INTO http_storage (key, value, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+	`
+
+	if _, err := s.pool.Exec(ctx, query, key, value, expiresAt); err != nil {
+		return fmt.Errorf("failed to set storage entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the value stored under a key
+func (s *PostgresStorage) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, "DELETE FROM http_storage WHERE key = $1", key); err != nil {
+		return fmt.Errorf("failed to delete storage entry: %w", err)
+	}
+	return nil
+}
+
+// Reset removes every entry
+func (s *PostgresStorage) Reset() error {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, "DELETE FROM http_storage"); err != nil {
+		return fmt.Errorf("failed to reset storage: %w", err)
+	}
+	return nil
+}
+
+// Close stops deleting expired entries. The pool stays open.
+func (s *PostgresStorage) Close() error {
+	close(s.stop)
+	<-s.done
+	return nil
+}
+
+// gc deletes expired entries every interval until the storage is closed
+func (s *PostgresStorage) gc(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		_, err := s.pool.Exec(ctx, "DELETE FROM http_storage WHERE expires_at <= NOW()")
+		cancel()
+		if err != nil {
+			slog.Warn("Failed to delete expired storage entries", "error", err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresTenantRepository implements TenantRepository for PostgreSQL
+type PostgresTenantRepository struct {
+	factory *PostgresFactory
+}
+
+// GetByHostname retrieves the tenant served on a hostname
+func (r *PostgresTenantRepository) GetByHostname(ctx context.Context, hostname string) (*Tenant, error) {
+	return r.getBy(ctx, "hostname", hostname)
+}
+
+// GetBySlug retrieves a tenant by its slug
+func (r *PostgresTenantRepository) GetBySlug(ctx context.Context, slug string) (*Tenant, error) {
+	return r.getBy(ctx, "slug", slug)
+}
+
+// List lists all tenants
+func (r *PostgresTenantRepository) List(ctx context.Context) ([]*Tenant, error) {
+	query := `
+		SELECT id, slug, name, COALESCE(hostname, ''), created_at, updated_at
+		FROM tenants
+		ORDER BY slug
+	`
+
+	rows, err := r.factory.getQueryer().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*Tenant{}
+	for rows.Next() {
+		var tenant Tenant
+		err := rows.Scan(
+			&tenant.ID, &tenant.Slug, &tenant.Name, &tenant.Hostname,
+			&tenant.CreatedAt, &tenant.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+
+		tenants = append(tenants, &tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenant rows: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// getBy retrieves a tenant by a unique column
+func (r *PostgresTenantRepository) getBy(ctx context.Context, column, value string) (*Tenant, error) {
+	query := fmt.Sprintf(`
+		SELECT id, slug, name, COALESCE(hostname, ''), created_at, updated_at
+		FROM tenants
+		WHERE %s = $1
+	`, column)
+
+	var tenant Tenant
+	err := r.factory.getQueryer().QueryRow(ctx, query, value).Scan(
+		&tenant.ID, &tenant.Slug, &tenant.Name, &tenant.Hostname,
+		&tenant.CreatedAt, &tenant.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: tenant with %s %s", ErrNotFound, column, value)
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	return &tenant, nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans started for statements
+const tracerName = "github.com/gfurduy/byebob/internal/repository"
+
+// repositoryFuncPrefix identifies the repository methods in stack frames
+const repositoryFuncPrefix = "github.com/gfurduy/byebob/internal/repository.(*Postgres"
+
+// unknownMethod names queries not issued by a repository method
+const unknownMethod = "other"
+
+// QueryStat aggregates the timings of the queries issued by one repository method
+type QueryStat struct {
+	Method    string        `json:"method"`
+	Count     int64         `json:"count"`
+	Errors    int64         `json:"errors"`
+	Slow      int64         `json:"slow"`
+	TotalTime time.Duration `json:"total_time_ns"`
+	MaxTime   time.Duration `json:"max_time_ns"`
+}
+
+// QueryTracer is a pgx query tracer that times every statement, aggregates
+// the timings per repository method, logs statements slower than a threshold
+// and records a span per statement under the span of the calling context.
+// It keeps two aggregates: stats, which Reset clears, and totals since the
+// tracer was created, which only grow and back the Prometheus counters.
+type QueryTracer struct {
+	slowThreshold time.Duration
+
+	mu     sync.Mutex
+	stats  map[string]*QueryStat
+	totals map[string]*QueryStat
+}
+
+// NewQueryTracer creates a tracer logging statements slower than the
+// threshold. A zero threshold disables slow-query logging.
+func NewQueryTracer(slowThreshold time.Duration) *QueryTracer {
+	return &QueryTracer{
+		slowThreshold: slowThreshold,
+		stats:         make(map[string]*QueryStat),
+		totals:        make(map[string]*QueryStat),
+	}
+}
+
+// queryTraceKey is the context key carrying a traced statement
+type queryTraceKey struct{}
+
+// queryTrace is a statement in flight
+type queryTrace struct {
+	method string
+	sql    string
+	start  time.Time
+	span   trace.Span
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	method := callerMethod()
+	ctx, span := otel.Tracer(tracerName).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(compactSQL(data.SQL)),
+		),
+	)
+	return context.WithValue(ctx, queryTraceKey{}, &queryTrace{
+		method: method,
+		sql:    data.SQL,
+		start:  time.Now(),
+		span:   span,
+	})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	query, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(query.start)
+	slow := t.slowThreshold > 0 && elapsed >= t.slowThreshold
+
+	if data.Err != nil {
+		query.span.RecordError(data.Err)
+		query.span.SetStatus(codes.Error, data.Err.Error())
+	}
+	query.span.End()
+
+	t.mu.Lock()
+	record(t.stats, query.method, elapsed, data.Err != nil, slow)
+	record(t.totals, query.method, elapsed, data.Err != nil, slow)
+	t.mu.Unlock()
+
+	if slow {
+		slog.WarnContext(ctx, "Slow query", "method", query.method, "duration", elapsed.Round(time.Millisecond), "sql", compactSQL(query.sql))
+	}
+}
+
+// record adds a statement to the statistics of its method
+func record(stats map[string]*QueryStat, method string, elapsed time.Duration, failed, slow bool) {
+	stat, ok := stats[method]
+	if !ok {
+		stat = &QueryStat{Method: method}
+		stats[method] = stat
+	}
+	stat.Count++
+	stat.TotalTime += elapsed
+	if elapsed > stat.MaxTime {
+		stat.MaxTime = elapsed
+	}
+	if failed {
+		stat.Errors++
+	}
+	if slow {
+		stat.Slow++
+	}
+}
+
+// Stats returns the per-method query statistics since the last reset,
+// slowest in total first
+func (t *QueryTracer) Stats() []QueryStat {
+	return t.snapshot(t.stats)
+}
+
+// Totals returns the per-method query statistics since the tracer was
+// created, slowest in total first. Reset does not affect them.
+func (t *QueryTracer) Totals() []QueryStat {
+	return t.snapshot(t.totals)
+}
+
+// snapshot copies one of the aggregates, slowest in total first
+func (t *QueryTracer) snapshot(aggregate map[string]*QueryStat) []QueryStat {
+	t.mu.Lock()
+	stats := make([]QueryStat, 0, len(aggregate))
+	for _, stat := range aggregate {
+		stats = append(stats, *stat)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(stats, func(a, b int) bool {
+		return stats[a].TotalTime > stats[b].TotalTime
+	})
+	return stats
+}
+
+// Reset clears the query statistics returned by Stats, but not the totals
+func (t *QueryTracer) Reset() {
+	t.mu.Lock()
+	clear(t.stats)
+	t.mu.Unlock()
+}
+
+// callerMethod returns the repository method issuing the current statement,
+// such as "EmployeeRepository.List"
+func callerMethod() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, repositoryFuncPrefix) {
+			method := strings.TrimPrefix(frame.Function, repositoryFuncPrefix)
+			method = strings.Replace(method, ").", ".", 1)
+			if i := strings.Index(method, ".func"); i >= 0 {
+				method = method[:i]
+			}
+			return method
+		}
+		if !more {
+			return unknownMethod
+		}
+	}
+}
+
+// compactSQL collapses whitespace so a statement fits on one log line
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplicaPool is a read replica that read-only statements are routed to
+// while its replication lag stays within bounds
+type ReplicaPool struct {
+	Pool    *pgxpool.Pool
+	maxLag  time.Duration
+	lag     atomic.Int64
+	healthy atomic.Bool
+}
+
+// NewReplicaPool wraps a replica connection pool. Reads go to the primary
+// until the first lag check succeeds.
+func NewReplicaPool(pool *pgxpool.Pool, maxLag time.Duration) *ReplicaPool {
+	return &ReplicaPool{
+		Pool:   pool,
+		maxLag: maxLag,
+	}
+}
+
+// Healthy reports whether reads may be routed to the replica
+func (r *ReplicaPool) Healthy() bool {
+	return r != nil && r.healthy.Load()
+}
+
+// Lag returns the replication lag measured by the last check
+func (r *ReplicaPool) Lag() time.Duration {
+	return time.Duration(r.lag.Load())
+}
+
+// Check measures the replication lag and marks the replica unhealthy when it
+// exceeds the maximum or cannot be measured. A replica that has replayed
+// everything it received has no lag, however old its last transaction.
+func (r *ReplicaPool) Check(ctx context.Context) error {
+	var seconds float64
+	err := r.Pool.QueryRow(ctx, `
+		SELECT CASE
+			WHEN NOT pg_is_in_recovery() THEN 0
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		END
+	`).Scan(&seconds)
+	if err != nil {
+		r.setHealthy(false)
+		return err
+	}
+
+	lag := time.Duration(seconds * float64(time.Second))
+	r.lag.Store(int64(lag))
+	r.setHealthy(lag <= r.maxLag)
+	return nil
+}
+
+// setHealthy records the replica state, logging changes
+func (r *ReplicaPool) setHealthy(healthy bool) {
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		slog.Info("Read replica is available; routing reads to it", "lag", r.Lag())
+	} else {
+		slog.Warn("Read replica is unavailable or lagging; routing reads to the primary", "max_lag", r.maxLag)
+	}
+}
+
+// Monitor checks the replication lag every interval until the context ends
+func (r *ReplicaPool) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		if err := r.Check(checkCtx); err != nil && ctx.Err() == nil {
+			slog.Warn("Read replica lag check failed", "error", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readYourWritesKey is the context key carrying a request's write marker
+type readYourWritesKey struct{}
+
+// ContextWithReadYourWrites returns a context whose reads go to the primary
+// once any statement in it has written, so a request sees its own changes
+// even though the replica has not caught up yet
+func ContextWithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, &atomic.Bool{})
+}
+
+// markWrite records that the context has written to the primary
+func markWrite(ctx context.Context) {
+	if wrote, ok := ctx.Value(readYourWritesKey{}).(*atomic.Bool); ok {
+		wrote.Store(true)
+	}
+}
+
+// hasWritten reports whether the context has written to the primary
+func hasWritten(ctx context.Context) bool {
+	wrote, ok := ctx.Value(readYourWritesKey{}).(*atomic.Bool)
+	return ok && wrote.Load()
+}
+
+// isReadOnly reports whether a statement only reads: a SELECT, or a WITH
+// query whose parts do not modify data
+func isReadOnly(sql string) bool {
+	statement := strings.ToUpper(strings.TrimSpace(sql))
+	switch {
+	case strings.HasPrefix(statement, "SELECT"):
+		return !strings.Contains(statement, " FOR UPDATE") && !strings.Contains(statement, " FOR SHARE")
+	case strings.HasPrefix(statement, "WITH"):
+		for _, keyword := range []string{"INSERT ", "UPDATE ", "DELETE ", "MERGE "} {
+			if strings.Contains(statement, keyword) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSearchTerms bounds how many words of a search query are matched
+const maxSearchTerms = 8
+
+// searchSimilarityThreshold is the pg_trgm word similarity above which a
+// field counts as a fuzzy match, low enough to forgive a swapped or missing
+// letter in a name. Every connection sets pg_trgm.word_similarity_threshold
+// to it, so the <% operator can find matches through the trigram indexes.
+const searchSimilarityThreshold = 0.4
+
+// SearchTerms splits a search query into lower-case words, dropping
+// punctuation, so "O'Brien, Ana" searches for "o", "brien" and "ana"
+func SearchTerms(query string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !isSearchRune(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// isSearchRune reports whether a rune is part of a search term
+func isSearchRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// prefixQuery builds a tsquery source matching documents that contain every
+// term as a word prefix. Terms only hold letters and digits, so they need no
+// quoting.
+func prefixQuery(terms []string) string {
+	return joinPrefixes(terms, " & ")
+}
+
+// anyPrefixQuery builds a tsquery source matching documents that contain any
+// term as a word prefix. A document built from several sources that matches
+// prefixQuery matches this in at least one of them, so it finds candidates
+// through each source's index.
+func anyPrefixQuery(terms []string) string {
+	return joinPrefixes(terms, " | ")
+}
+
+// joinPrefixes joins the terms as word prefixes with a tsquery operator
+func joinPrefixes(terms []string, operator string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, operator)
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// tenantContextKey is the context key carrying the current tenant ID
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a context whose queries are scoped to the tenant
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID carried by the context, if any
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// Actor identifies who queries run on behalf of. Row-level security limits
+// the app role to the actor, their reporting subtree, or everyone for HR.
+type Actor struct {
+	EmployeeID string
+	HR         bool
+}
+
+// SystemActor is used by background jobs that act on all employees of a tenant
+var SystemActor = Actor{HR: true}
+
+// actorContextKey is the context key carrying the current actor
+type actorContextKey struct{}
+
+// ContextWithActor returns a context whose queries run on behalf of the actor
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by the context, if any
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorContextKey{}).(Actor)
+	return actor, ok
+}
+
+// hasSession reports whether the context carries a tenant or an actor
+func hasSession(ctx context.Context) bool {
+	_, hasTenant := TenantFromContext(ctx)
+	_, hasActor := ActorFromContext(ctx)
+	return hasTenant || hasActor
+}
+
+// setSession scopes a transaction to the tenant and actor carried by the
+// context, like SET LOCAL app.tenant_id, app.user_id and app.user_is_hr.
+// Row-level security policies read these settings.
+func setSession(ctx context.Context, tx pgx.Tx) error {
+	tenantID, _ := TenantFromContext(ctx)
+	actor, _ := ActorFromContext(ctx)
+
+	_, err := tx.Exec(ctx, `
+		SELECT set_config('app.tenant_id', $1, true),
+			set_config('app.user_id', $2, true),
+			set_config('app.user_is_hr', $3, true)
+	`, tenantID, actor.EmployeeID, strconv.FormatBool(actor.HR))
+	if err != nil {
+		return fmt.Errorf("failed to set session: %w", err)
+	}
+	return nil
+}
+
+// scopedQueryer runs each statement outside an explicit transaction in its
+// own transaction scoped to the tenant and actor carried by the context.
+// Statements without either run as is, and row-level security hides tenant
+// data from them. Read-only statements go to the replica when there is a
+// healthy one and the context has not written yet.
+type scopedQueryer struct {
+	pool    *pgxpool.Pool
+	replica *ReplicaPool
+}
+
+// route picks the pool for a statement
+func (q scopedQueryer) route(ctx context.Context, sql string) *pgxpool.Pool {
+	if !isReadOnly(sql) {
+		markWrite(ctx)
+		return q.pool
+	}
+	if q.replica.Healthy() && !hasWritten(ctx) {
+		return q.replica.Pool
+	}
+	return q.pool
+}
+
+// begin starts a scoped transaction
+func (q scopedQueryer) begin(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	if err := setSession(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Exec implements queryer
+func (q scopedQueryer) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	markWrite(ctx)
+	if !hasSession(ctx) {
+		return q.pool.Exec(ctx, sql, args...)
+	}
+
+	tx, err := q.begin(ctx, q.pool)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return tag, err
+	}
+	return tag, tx.Commit(ctx)
+}
+
+// Query implements queryer. The transaction ends when the rows are closed.
+func (q scopedQueryer) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	pool := q.route(ctx, sql)
+	if !hasSession(ctx) {
+		return pool.Query(ctx, sql, args...)
+	}
+
+	tx, err := q.begin(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return &scopedRows{Rows: rows, ctx: ctx, tx: tx}, nil
+}
+
+// QueryRow implements queryer. The transaction ends when the row is scanned.
+func (q scopedQueryer) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	pool := q.route(ctx, sql)
+	if !hasSession(ctx) {
+		return pool.QueryRow(ctx, sql, args...)
+	}
+
+	tx, err := q.begin(ctx, pool)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return &scopedRow{row: tx.QueryRow(ctx, sql, args...), ctx: ctx, tx: tx}
+}
+
+// scopedRows ends its transaction when closed
+type scopedRows struct {
+	pgx.Rows
+	ctx    context.Context
+	tx     pgx.Tx
+	closed bool
+}
+
+// Close closes the rows and ends the transaction
+func (r *scopedRows) Close() {
+	r.Rows.Close()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	if r.Rows.Err() != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return
+	}
+	_ = r.tx.Commit(r.ctx)
+}
+
+// scopedRow ends its transaction once scanned
+type scopedRow struct {
+	row pgx.Row
+	ctx context.Context
+	tx  pgx.Tx
+}
+
+// Scan scans the row and commits, or rolls back if the scan failed
+func (r *scopedRow) Scan(dest ...interface{}) error {
+	if err := r.row.Scan(dest...); err != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return err
+	}
+	return r.tx.Commit(r.ctx)
+}
+
+// errRow is a row that fails to scan with a fixed error
+type errRow struct {
+	err error
+}
+
+// Scan returns the error
+func (r errRow) Scan(dest ...interface{}) error {
+	return r.err
+}
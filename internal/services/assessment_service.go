@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// Assessment statuses
+const (
+	AssessmentPending    = "pending"
+	AssessmentInProgress = "in_progress"
+	AssessmentCompleted  = "completed"
+	AssessmentCancelled  = "cancelled"
+)
+
+// AssessmentStatuses lists every valid assessment status
+var AssessmentStatuses = []string{AssessmentPending, AssessmentInProgress, AssessmentCompleted, AssessmentCancelled}
+
+// assessmentTransitions lists the statuses reachable from each status
+var assessmentTransitions = map[string][]string{
+	AssessmentPending:    {AssessmentInProgress, AssessmentCancelled},
+	AssessmentInProgress: {AssessmentCompleted, AssessmentCancelled},
+}
+
+// AssessmentFilter narrows down assessment listings
+type AssessmentFilter struct {
+	EmployeeID string
+	ReviewerID string
+	Status     string
+}
+
+// toMap converts the filter into repository column filters
+func (f AssessmentFilter) toMap() map[string]interface{} {
+	filters := map[string]interface{}{}
+	if f.EmployeeID != "" {
+		filters["employee_id"] = f.EmployeeID
+	}
+	if f.ReviewerID != "" {
+		filters["reviewer_id"] = f.ReviewerID
+	}
+	if f.Status != "" {
+		filters["status"] = f.Status
+	}
+	return filters
+}
+
+// AssessmentService handles performance assessment business logic
+type AssessmentService struct {
+	repos repository.RepositoryFactory
+}
+
+// NewAssessmentService creates a new assessment service
+func NewAssessmentService(repos repository.RepositoryFactory) *AssessmentService {
+	return &AssessmentService{
+		repos: repos,
+	}
+}
+
+// List retrieves a page of assessments matching the filter
+func (s *AssessmentService) List(ctx context.Context, filter AssessmentFilter, limit, offset int) ([]*repository.Assessment, int64, error) {
+	ctx, span := startSpan(ctx, "AssessmentService.List")
+	defer span.End()
+
+	v := &validator{}
+	v.uuid("employee_id", filter.EmployeeID)
+	v.uuid("reviewer_id", filter.ReviewerID)
+	v.oneOf("status", filter.Status, AssessmentStatuses...)
+	if err := v.err(); err != nil {
+		return nil, 0, err
+	}
+
+	limit, offset = NormalizePage(limit, offset)
+	assessments, total, err := s.repos.Assessments().List(ctx, filter.toMap(), limit, offset)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	return assessments, total, nil
+}
+
+// Get retrieves an assessment by ID
+func (s *AssessmentService) Get(ctx context.Context, id string) (*repository.Assessment, error) {
+	ctx, span := startSpan(ctx, "AssessmentService.Get")
+	defer span.End()
+
+	if err := validateID("assessment", id); err != nil {
+		return nil, err
+	}
+	assessment, err := s.repos.Assessments().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return assessment, nil
+}
+
+// Create validates and opens a new pending assessment
+func (s *AssessmentService) Create(ctx context.Context, assessment *repository.Assessment) (*repository.Assessment, error) {
+	ctx, span := startSpan(ctx, "AssessmentService.Create")
+	defer span.End()
+
+	assessment.Status = AssessmentPending
+	assessment.CompletedAt = time.Time{}
+
+	if err := s.validate(ctx, assessment); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repos.Assessments().Create(ctx, assessment)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Update changes the template or reviewer of an assessment that has not started
+func (s *AssessmentService) Update(ctx context.Context, assessment *repository.Assessment) (*repository.Assessment, error) {
+	ctx, span := startSpan(ctx, "AssessmentService.Update")
+	defer span.End()
+
+	if err := requireVersion(assessment.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.Get(ctx, assessment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status != AssessmentPending {
+		return nil, fmt.Errorf("%w: assessment %s is %s and can no longer be edited", ErrConflict, existing.ID, existing.Status)
+	}
+
+	// Status and subject are not editable here
+	assessment.Version = matchVersion(assessment.Version, existing.Version)
+	assessment.EmployeeID = existing.EmployeeID
+	assessment.Status = existing.Status
+	assessment.CompletedAt = existing.CompletedAt
+
+	if err := s.validate(ctx, assessment); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Assessments().Update(ctx, assessment); err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, assessment.ID)
+}
+
+// Transition moves an assessment to a new status following the workflow,
+// provided it is still at the given version
+func (s *AssessmentService) Transition(ctx context.Context, id, status string, version int) (*repository.Assessment, error) {
+	ctx, span := startSpan(ctx, "AssessmentService.Transition")
+	defer span.End()
+
+	if err := requireVersion(version); err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.required("status", status)
+	v.oneOf("status", status, AssessmentStatuses...)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	assessment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(assessmentTransitions[assessment.Status], status) {
+		return nil, fmt.Errorf("%w: assessment %s cannot move from %s to %s", ErrConflict, id, assessment.Status, status)
+	}
+
+	assessment.Version = matchVersion(version, assessment.Version)
+	assessment.Status = status
+	if status == AssessmentCompleted {
+		assessment.CompletedAt = time.Now().UTC()
+	}
+
+	if err := s.repos.Assessments().Update(ctx, assessment); err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Delete removes an assessment that has not started
+func (s *AssessmentService) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "AssessmentService.Delete")
+	defer span.End()
+
+	assessment, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if assessment.Status != AssessmentPending {
+		return fmt.Errorf("%w: assessment %s is %s and cannot be deleted", ErrConflict, id, assessment.Status)
+	}
+	return translateError(s.repos.Assessments().Delete(ctx, id))
+}
+
+// validate checks field rules and that the template and participants exist
+func (s *AssessmentService) validate(ctx context.Context, a *repository.Assessment) error {
+	v := &validator{}
+	v.required("template_id", a.TemplateID)
+	v.uuid("template_id", a.TemplateID)
+	v.required("employee_id", a.EmployeeID)
+	v.uuid("employee_id", a.EmployeeID)
+	v.required("reviewer_id", a.ReviewerID)
+	v.uuid("reviewer_id", a.ReviewerID)
+	if !v.valid() {
+		return v.err()
+	}
+
+	var template *repository.AssessmentTemplate
+	if err := checkReference(v, "template_id", func() (err error) {
+		template, err = s.repos.Assessments().GetTemplateByID(ctx, a.TemplateID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if template != nil && !template.Active {
+		v.add("template_id", "template is not active")
+	}
+	if err := checkReference(v, "employee_id", func() error {
+		_, err := s.repos.Employees().GetByID(ctx, a.EmployeeID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := checkReference(v, "reviewer_id", func() error {
+		_, err := s.repos.Employees().GetByID(ctx, a.ReviewerID)
+		return err
+	}); err != nil {
+		return err
+	}
+	return v.err()
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// Allowed values for employee fields
+var (
+	EmploymentTypes  = []string{"full_time", "part_time", "contractor", "intern", "temporary"}
+	EmployeeStatuses = []string{"active", "on_leave", "inactive", "terminated"}
+)
+
+// maxManagerDepth bounds the walk up the reporting line when checking for cycles
+const maxManagerDepth = 64
+
+// EmployeeFilter narrows down employee listings
+type EmployeeFilter struct {
+	DepartmentID string
+	SiteID       string
+	PositionID   string
+	ManagerID    string
+	Status       string
+	Address      string
+}
+
+// toMap converts the filter into repository column filters
+func (f EmployeeFilter) toMap() map[string]interface{} {
+	filters := map[string]interface{}{}
+	if f.DepartmentID != "" {
+		filters["department_id"] = f.DepartmentID
+	}
+	if f.SiteID != "" {
+		filters["site_id"] = f.SiteID
+	}
+	if f.PositionID != "" {
+		filters["position_id"] = f.PositionID
+	}
+	if f.ManagerID != "" {
+		filters["manager_id"] = f.ManagerID
+	}
+	if f.Status != "" {
+		filters["status"] = f.Status
+	}
+	if f.Address != "" {
+		filters["address"] = f.Address
+	}
+	return filters
+}
+
+// EmployeeService handles employee business logic
+type EmployeeService struct {
+	repos repository.RepositoryFactory
+}
+
+// NewEmployeeService creates a new employee service
+func NewEmployeeService(repos repository.RepositoryFactory) *EmployeeService {
+	return &EmployeeService{
+		repos: repos,
+	}
+}
+
+// List retrieves a page of employees matching the filter
+func (s *EmployeeService) List(ctx context.Context, filter EmployeeFilter, limit, offset int) ([]*repository.Employee, int64, error) {
+	ctx, span := startSpan(ctx, "EmployeeService.List")
+	defer span.End()
+
+	v := &validator{}
+	v.uuid("department_id", filter.DepartmentID)
+	v.uuid("site_id", filter.SiteID)
+	v.uuid("position_id", filter.PositionID)
+	v.uuid("manager_id", filter.ManagerID)
+	v.oneOf("status", filter.Status, EmployeeStatuses...)
+	if err := v.err(); err != nil {
+		return nil, 0, err
+	}
+
+	limit, offset = NormalizePage(limit, offset)
+	employees, total, err := s.repos.Employees().List(ctx, filter.toMap(), limit, offset)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	return employees, total, nil
+}
+
+// Get retrieves an employee by ID
+func (s *EmployeeService) Get(ctx context.Context, id string) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "EmployeeService.Get")
+	defer span.End()
+
+	if err := validateID("employee", id); err != nil {
+		return nil, err
+	}
+	employee, err := s.repos.Employees().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return employee, nil
+}
+
+// DirectReports retrieves the employees reporting to the given manager
+func (s *EmployeeService) DirectReports(ctx context.Context, managerID string) ([]*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "EmployeeService.DirectReports")
+	defer span.End()
+
+	if _, err := s.Get(ctx, managerID); err != nil {
+		return nil, err
+	}
+	employees, err := s.repos.Employees().GetByManager(ctx, managerID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return employees, nil
+}
+
+// Create validates and creates a new employee
+func (s *EmployeeService) Create(ctx context.Context, employee *repository.Employee) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "EmployeeService.Create")
+	defer span.End()
+
+	normalizeEmployee(employee)
+	if err := s.validate(ctx, employee); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueEmail(ctx, employee); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repos.Employees().Create(ctx, employee)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Update validates and updates an existing employee. Only HR edits every
+// field; employees change their own record through ProfileService.
+func (s *EmployeeService) Update(ctx context.Context, employee *repository.Employee) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "EmployeeService.Update")
+	defer span.End()
+
+	if err := requireVersion(employee.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.Get(ctx, employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	employee.Version = matchVersion(employee.Version, existing.Version)
+
+	normalizeEmployee(employee)
+	if err := s.validate(ctx, employee); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueEmail(ctx, employee); err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.Employees().Update(ctx, employee); err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, employee.ID)
+}
+
+// Delete removes an employee who no longer manages anyone
+func (s *EmployeeService) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "EmployeeService.Delete")
+	defer span.End()
+
+	reports, err := s.DirectReports(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(reports) > 0 {
+		return fmt.Errorf("%w: employee %s still has %d direct reports", ErrConflict, id, len(reports))
+	}
+
+	return translateError(s.repos.Employees().Delete(ctx, id))
+}
+
+// Restore brings back a deleted employee, provided their email is not in use
+func (s *EmployeeService) Restore(ctx context.Context, id string) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "EmployeeService.Restore")
+	defer span.End()
+
+	if err := validateID("employee", id); err != nil {
+		return nil, err
+	}
+	employee, err := s.repos.Employees().GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if err := s.ensureUniqueEmail(ctx, employee); err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.Employees().Restore(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// normalizeEmployee trims input and fills in derived defaults
+func normalizeEmployee(e *repository.Employee) {
+	e.FirstName = strings.TrimSpace(e.FirstName)
+	e.MiddleName = strings.TrimSpace(e.MiddleName)
+	e.LastName = strings.TrimSpace(e.LastName)
+	e.DisplayName = strings.TrimSpace(e.DisplayName)
+	e.Email = strings.ToLower(strings.TrimSpace(e.Email))
+
+	if e.DisplayName == "" {
+		e.DisplayName = strings.TrimSpace(e.FirstName + " " + e.LastName)
+	}
+	if e.Status == "" {
+		e.Status = "active"
+	}
+}
+
+// validate checks field rules and that referenced records exist
+func (s *EmployeeService) validate(ctx context.Context, e *repository.Employee) error {
+	v := &validator{}
+	v.required("first_name", e.FirstName)
+	v.maxLength("first_name", e.FirstName, 100)
+	v.maxLength("middle_name", e.MiddleName, 100)
+	v.required("last_name", e.LastName)
+	v.maxLength("last_name", e.LastName, 100)
+	v.maxLength("display_name", e.DisplayName, 200)
+	v.required("email", e.Email)
+	v.maxLength("email", e.Email, 255)
+	v.email("email", e.Email)
+	v.required("position_id", e.PositionID)
+	v.uuid("position_id", e.PositionID)
+	v.required("department_id", e.DepartmentID)
+	v.uuid("department_id", e.DepartmentID)
+	v.required("site_id", e.SiteID)
+	v.uuid("site_id", e.SiteID)
+	v.uuid("manager_id", e.ManagerID)
+	v.required("employment_type", e.EmploymentType)
+	v.oneOf("employment_type", e.EmploymentType, EmploymentTypes...)
+	v.oneOf("status", e.Status, EmployeeStatuses...)
+	v.check(!e.StartDate.IsZero(), "start_date", "is required")
+	v.check(e.EndDate.IsZero() || !e.EndDate.Before(e.StartDate), "end_date", "must not be before start_date")
+	v.check(e.ManagerID == "" || e.ManagerID != e.ID, "manager_id", "an employee cannot manage themselves")
+	if !v.valid() {
+		return v.err()
+	}
+
+	// Referenced records must exist
+	if err := checkReference(v, "position_id", func() error {
+		_, err := s.repos.Positions().GetByID(ctx, e.PositionID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := checkReference(v, "department_id", func() error {
+		_, err := s.repos.Departments().GetByID(ctx, e.DepartmentID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := checkReference(v, "site_id", func() error {
+		_, err := s.repos.Sites().GetByID(ctx, e.SiteID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if e.ManagerID != "" {
+		if err := s.checkReportingLine(ctx, v, e); err != nil {
+			return err
+		}
+	}
+
+	return v.err()
+}
+
+// checkReportingLine verifies the manager exists and that assigning them does
+// not create a cycle in the reporting tree
+func (s *EmployeeService) checkReportingLine(ctx context.Context, v *validator, e *repository.Employee) error {
+	managerID := e.ManagerID
+	for depth := 0; managerID != "" && depth < maxManagerDepth; depth++ {
+		manager, err := s.repos.Employees().GetByID(ctx, managerID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				if depth == 0 {
+					v.add("manager_id", "does not exist")
+				}
+				return nil
+			}
+			return err
+		}
+		if e.ID != "" && manager.ManagerID == e.ID {
+			v.add("manager_id", "would create a cycle in the reporting line")
+			return nil
+		}
+		managerID = manager.ManagerID
+	}
+	return nil
+}
+
+// ensureUniqueEmail reports a conflict if another employee uses the same email
+func (s *EmployeeService) ensureUniqueEmail(ctx context.Context, e *repository.Employee) error {
+	existing, _, err := s.repos.Employees().List(ctx, map[string]interface{}{"email": e.Email}, 1, 0)
+	if err != nil {
+		return translateError(err)
+	}
+	if len(existing) > 0 && existing[0].ID != e.ID {
+		return &FieldConflictError{
+			Kind:   ErrConflict,
+			Detail: fmt.Sprintf("an employee with email %s already exists", e.Email),
+			Fields: []FieldError{{Field: "email", Message: "is already in use"}},
+		}
+	}
+	return nil
+}
+
+// checkReference runs a lookup and records a field error if the record is missing
+func checkReference(v *validator, field string, lookup func() error) error {
+	err := lookup()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		v.add(field, "does not exist")
+		return nil
+	}
+	return err
+}
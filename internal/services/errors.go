@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// Domain errors returned by the service layer. Callers should match them with
+// errors.Is, since they are usually wrapped with additional detail.
+var (
+	ErrNotFound   = errors.New("resource not found")
+	ErrConflict   = errors.New("resource conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+)
+
+// FieldError describes a validation failure on a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries the field-level details of a failed validation.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(parts, "; "))
+}
+
+// Is reports whether the target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// NewValidationError returns a ValidationError for a single field
+func NewValidationError(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// FieldConflictError is a non-validation domain error tied to specific fields,
+// such as a duplicate unique value. It matches its Kind with errors.Is.
+type FieldConflictError struct {
+	Kind   error
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+// Error implements the error interface
+func (e *FieldConflictError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Detail)
+}
+
+// Is reports whether the target is the kind of error
+func (e *FieldConflictError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying error
+func (e *FieldConflictError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrorsOf returns the field details carried by an error, if any
+func FieldErrorsOf(err error) []FieldError {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	var conflictErr *FieldConflictError
+	if errors.As(err, &conflictErr) {
+		return conflictErr.Fields
+	}
+	return nil
+}
+
+// translateError converts repository errors into domain errors
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+
+	var ce *repository.ConstraintError
+	if !errors.As(err, &ce) {
+		return err
+	}
+
+	field := ce.Field
+	if field == "" {
+		field = ce.Table
+	}
+
+	switch {
+	case errors.Is(ce, repository.ErrDuplicate):
+		return &FieldConflictError{
+			Kind:   ErrConflict,
+			Detail: fmt.Sprintf("%s is already in use", field),
+			Fields: []FieldError{{Field: field, Message: "is already in use"}},
+			Err:    err,
+		}
+	case errors.Is(ce, repository.ErrStillReferenced):
+		return &FieldConflictError{
+			Kind:   ErrConflict,
+			Detail: fmt.Sprintf("record is still referenced by %s", ce.Table),
+			Err:    err,
+		}
+	case errors.Is(ce, repository.ErrSensitiveFieldUpdate):
+		return &FieldConflictError{
+			Kind:   ErrForbidden,
+			Detail: fmt.Sprintf("not authorized to modify %s", field),
+			Fields: []FieldError{{Field: field, Message: "can only be changed by an administrator"}},
+			Err:    err,
+		}
+	case errors.Is(ce, repository.ErrInvalidReference):
+		return NewValidationError(field, "does not exist")
+	case errors.Is(ce, repository.ErrRequired):
+		return NewValidationError(field, "is required")
+	case errors.Is(ce, repository.ErrCheckViolation):
+		return NewValidationError(field, "has a value that is not allowed")
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// Goal statuses
+const (
+	GoalActive    = "active"
+	GoalCompleted = "completed"
+	GoalCancelled = "cancelled"
+)
+
+// Allowed values for goal fields
+var (
+	GoalStatuses = []string{GoalActive, GoalCompleted, GoalCancelled}
+	GoalTypes    = []string{"individual", "team", "company"}
+)
+
+// GoalService handles goal and check-in business logic
+type GoalService struct {
+	repos repository.RepositoryFactory
+}
+
+// NewGoalService creates a new goal service
+func NewGoalService(repos repository.RepositoryFactory) *GoalService {
+	return &GoalService{
+		repos: repos,
+	}
+}
+
+// ListByEmployee retrieves all goals of an employee
+func (s *GoalService) ListByEmployee(ctx context.Context, employeeID string) ([]*repository.Goal, error) {
+	ctx, span := startSpan(ctx, "GoalService.ListByEmployee")
+	defer span.End()
+
+	if err := validateID("employee", employeeID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repos.Employees().GetByID(ctx, employeeID); err != nil {
+		return nil, translateError(err)
+	}
+	goals, err := s.repos.Goals().GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return goals, nil
+}
+
+// Get retrieves a goal by ID
+func (s *GoalService) Get(ctx context.Context, id string) (*repository.Goal, error) {
+	ctx, span := startSpan(ctx, "GoalService.Get")
+	defer span.End()
+
+	if err := validateID("goal", id); err != nil {
+		return nil, err
+	}
+	goal, err := s.repos.Goals().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return goal, nil
+}
+
+// Create validates and creates a new active goal
+func (s *GoalService) Create(ctx context.Context, goal *repository.Goal) (*repository.Goal, error) {
+	ctx, span := startSpan(ctx, "GoalService.Create")
+	defer span.End()
+
+	goal.Status = GoalActive
+	if err := s.validate(ctx, goal); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repos.Goals().Create(ctx, goal)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Update validates and updates a goal that is still active
+func (s *GoalService) Update(ctx context.Context, goal *repository.Goal) (*repository.Goal, error) {
+	ctx, span := startSpan(ctx, "GoalService.Update")
+	defer span.End()
+
+	if err := requireVersion(goal.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.Get(ctx, goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status != GoalActive {
+		return nil, fmt.Errorf("%w: goal %s is %s and can no longer be edited", ErrConflict, existing.ID, existing.Status)
+	}
+
+	// Goals cannot be moved to another employee
+	goal.Version = matchVersion(goal.Version, existing.Version)
+	goal.EmployeeID = existing.EmployeeID
+	if goal.Status == "" {
+		goal.Status = existing.Status
+	}
+
+	if err := s.validate(ctx, goal); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Goals().Update(ctx, goal); err != nil {
+		return nil, translateError(err)
+	}
+	return s.Get(ctx, goal.ID)
+}
+
+// Delete removes a goal and its check-ins
+func (s *GoalService) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "GoalService.Delete")
+	defer span.End()
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return translateError(s.repos.Goals().Delete(ctx, id))
+}
+
+// ListCheckIns retrieves the check-ins recorded on a goal
+func (s *GoalService) ListCheckIns(ctx context.Context, goalID string) ([]*repository.GoalCheckIn, error) {
+	ctx, span := startSpan(ctx, "GoalService.ListCheckIns")
+	defer span.End()
+
+	if _, err := s.Get(ctx, goalID); err != nil {
+		return nil, err
+	}
+	checkIns, err := s.repos.Goals().ListCheckIns(ctx, goalID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return checkIns, nil
+}
+
+// AddCheckIn records progress on an active goal
+func (s *GoalService) AddCheckIn(ctx context.Context, checkIn *repository.GoalCheckIn) (*repository.GoalCheckIn, error) {
+	ctx, span := startSpan(ctx, "GoalService.AddCheckIn")
+	defer span.End()
+
+	goal, err := s.Get(ctx, checkIn.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status != GoalActive {
+		return nil, fmt.Errorf("%w: goal %s is %s and does not accept check-ins", ErrConflict, goal.ID, goal.Status)
+	}
+
+	checkIn.Note = strings.TrimSpace(checkIn.Note)
+
+	v := &validator{}
+	v.check(checkIn.Progress >= 0 && checkIn.Progress <= 100, "progress", "must be between 0 and 100")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	id, err := s.repos.Goals().AddCheckIn(ctx, checkIn)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	checkIn.ID = id
+	return checkIn, nil
+}
+
+// validate checks goal field rules and that the employee exists
+func (s *GoalService) validate(ctx context.Context, g *repository.Goal) error {
+	g.Title = strings.TrimSpace(g.Title)
+	g.TimeFrame = strings.TrimSpace(g.TimeFrame)
+
+	v := &validator{}
+	v.required("employee_id", g.EmployeeID)
+	v.uuid("employee_id", g.EmployeeID)
+	v.required("title", g.Title)
+	v.maxLength("title", g.Title, 200)
+	v.required("time_frame", g.TimeFrame)
+	v.maxLength("time_frame", g.TimeFrame, 50)
+	v.required("type", g.Type)
+	v.oneOf("type", g.Type, GoalTypes...)
+	v.oneOf("status", g.Status, GoalStatuses...)
+	if !v.valid() {
+		return v.err()
+	}
+
+	if err := checkReference(v, "employee_id", func() error {
+		_, err := s.repos.Employees().GetByID(ctx, g.EmployeeID)
+		return err
+	}); err != nil {
+		return err
+	}
+	return v.err()
+}
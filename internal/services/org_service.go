@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// OrgService handles the organisation structure: positions, departments and sites
+type OrgService struct {
+	repos repository.RepositoryFactory
+}
+
+// NewOrgService creates a new organisation service
+func NewOrgService(repos repository.RepositoryFactory) *OrgService {
+	return &OrgService{
+		repos: repos,
+	}
+}
+
+// ListPositions retrieves a page of positions
+func (s *OrgService) ListPositions(ctx context.Context, limit, offset int) ([]*repository.Position, int64, error) {
+	ctx, span := startSpan(ctx, "OrgService.ListPositions")
+	defer span.End()
+
+	limit, offset = NormalizePage(limit, offset)
+	positions, total, err := s.repos.Positions().List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	return positions, total, nil
+}
+
+// GetPosition retrieves a position by ID
+func (s *OrgService) GetPosition(ctx context.Context, id string) (*repository.Position, error) {
+	ctx, span := startSpan(ctx, "OrgService.GetPosition")
+	defer span.End()
+
+	if err := validateID("position", id); err != nil {
+		return nil, err
+	}
+	position, err := s.repos.Positions().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return position, nil
+}
+
+// CreatePosition validates and creates a new position
+func (s *OrgService) CreatePosition(ctx context.Context, position *repository.Position) (*repository.Position, error) {
+	ctx, span := startSpan(ctx, "OrgService.CreatePosition")
+	defer span.End()
+
+	if err := validatePosition(position); err != nil {
+		return nil, err
+	}
+	id, err := s.repos.Positions().Create(ctx, position)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetPosition(ctx, id)
+}
+
+// UpdatePosition validates and updates an existing position
+func (s *OrgService) UpdatePosition(ctx context.Context, position *repository.Position) (*repository.Position, error) {
+	ctx, span := startSpan(ctx, "OrgService.UpdatePosition")
+	defer span.End()
+
+	if err := requireVersion(position.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetPosition(ctx, position.ID)
+	if err != nil {
+		return nil, err
+	}
+	position.Version = matchVersion(position.Version, existing.Version)
+	if err := validatePosition(position); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Positions().Update(ctx, position); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetPosition(ctx, position.ID)
+}
+
+// DeletePosition removes a position that no employee holds
+func (s *OrgService) DeletePosition(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "OrgService.DeletePosition")
+	defer span.End()
+
+	if _, err := s.GetPosition(ctx, id); err != nil {
+		return err
+	}
+	if err := s.ensureUnused(ctx, "position", "position_id", id); err != nil {
+		return err
+	}
+	return translateError(s.repos.Positions().Delete(ctx, id))
+}
+
+// RestorePosition brings back a deleted position
+func (s *OrgService) RestorePosition(ctx context.Context, id string) (*repository.Position, error) {
+	ctx, span := startSpan(ctx, "OrgService.RestorePosition")
+	defer span.End()
+
+	if err := validateID("position", id); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Positions().Restore(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetPosition(ctx, id)
+}
+
+// ListDepartments retrieves a page of departments
+func (s *OrgService) ListDepartments(ctx context.Context, limit, offset int) ([]*repository.Department, int64, error) {
+	ctx, span := startSpan(ctx, "OrgService.ListDepartments")
+	defer span.End()
+
+	limit, offset = NormalizePage(limit, offset)
+	departments, total, err := s.repos.Departments().List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	return departments, total, nil
+}
+
+// GetDepartment retrieves a department by ID
+func (s *OrgService) GetDepartment(ctx context.Context, id string) (*repository.Department, error) {
+	ctx, span := startSpan(ctx, "OrgService.GetDepartment")
+	defer span.End()
+
+	if err := validateID("department", id); err != nil {
+		return nil, err
+	}
+	department, err := s.repos.Departments().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return department, nil
+}
+
+// CreateDepartment validates and creates a new department
+func (s *OrgService) CreateDepartment(ctx context.Context, department *repository.Department) (*repository.Department, error) {
+	ctx, span := startSpan(ctx, "OrgService.CreateDepartment")
+	defer span.End()
+
+	if err := s.validateDepartment(ctx, department); err != nil {
+		return nil, err
+	}
+	id, err := s.repos.Departments().Create(ctx, department)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetDepartment(ctx, id)
+}
+
+// UpdateDepartment validates and updates an existing department
+func (s *OrgService) UpdateDepartment(ctx context.Context, department *repository.Department) (*repository.Department, error) {
+	ctx, span := startSpan(ctx, "OrgService.UpdateDepartment")
+	defer span.End()
+
+	if err := requireVersion(department.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetDepartment(ctx, department.ID)
+	if err != nil {
+		return nil, err
+	}
+	department.Version = matchVersion(department.Version, existing.Version)
+	if err := s.validateDepartment(ctx, department); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Departments().Update(ctx, department); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetDepartment(ctx, department.ID)
+}
+
+// DeleteDepartment removes a department that has no employees
+func (s *OrgService) DeleteDepartment(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "OrgService.DeleteDepartment")
+	defer span.End()
+
+	if _, err := s.GetDepartment(ctx, id); err != nil {
+		return err
+	}
+	if err := s.ensureUnused(ctx, "department", "department_id", id); err != nil {
+		return err
+	}
+	return translateError(s.repos.Departments().Delete(ctx, id))
+}
+
+// RestoreDepartment brings back a deleted department
+func (s *OrgService) RestoreDepartment(ctx context.Context, id string) (*repository.Department, error) {
+	ctx, span := startSpan(ctx, "OrgService.RestoreDepartment")
+	defer span.End()
+
+	if err := validateID("department", id); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Departments().Restore(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetDepartment(ctx, id)
+}
+
+// ListSites retrieves a page of sites
+func (s *OrgService) ListSites(ctx context.Context, limit, offset int) ([]*repository.Site, int64, error) {
+	ctx, span := startSpan(ctx, "OrgService.ListSites")
+	defer span.End()
+
+	limit, offset = NormalizePage(limit, offset)
+	sites, total, err := s.repos.Sites().List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	return sites, total, nil
+}
+
+// GetSite retrieves a site by ID
+func (s *OrgService) GetSite(ctx context.Context, id string) (*repository.Site, error) {
+	ctx, span := startSpan(ctx, "OrgService.GetSite")
+	defer span.End()
+
+	if err := validateID("site", id); err != nil {
+		return nil, err
+	}
+	site, err := s.repos.Sites().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return site, nil
+}
+
+// CreateSite validates and creates a new site
+func (s *OrgService) CreateSite(ctx context.Context, site *repository.Site) (*repository.Site, error) {
+	ctx, span := startSpan(ctx, "OrgService.CreateSite")
+	defer span.End()
+
+	if err := validateSite(site); err != nil {
+		return nil, err
+	}
+	id, err := s.repos.Sites().Create(ctx, site)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetSite(ctx, id)
+}
+
+// UpdateSite validates and updates an existing site
+func (s *OrgService) UpdateSite(ctx context.Context, site *repository.Site) (*repository.Site, error) {
+	ctx, span := startSpan(ctx, "OrgService.UpdateSite")
+	defer span.End()
+
+	if err := requireVersion(site.Version); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetSite(ctx, site.ID)
+	if err != nil {
+		return nil, err
+	}
+	site.Version = matchVersion(site.Version, existing.Version)
+	if err := validateSite(site); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Sites().Update(ctx, site); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetSite(ctx, site.ID)
+}
+
+// DeleteSite removes a site that has no employees
+func (s *OrgService) DeleteSite(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "OrgService.DeleteSite")
+	defer span.End()
+
+	if _, err := s.GetSite(ctx, id); err != nil {
+		return err
+	}
+	if err := s.ensureUnused(ctx, "site", "site_id", id); err != nil {
+		return err
+	}
+	return translateError(s.repos.Sites().Delete(ctx, id))
+}
+
+// RestoreSite brings back a deleted site
+func (s *OrgService) RestoreSite(ctx context.Context, id string) (*repository.Site, error) {
+	ctx, span := startSpan(ctx, "OrgService.RestoreSite")
+	defer span.End()
+
+	if err := validateID("site", id); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Sites().Restore(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetSite(ctx, id)
+}
+
+// validatePosition checks position field rules
+func validatePosition(p *repository.Position) error {
+	p.Title = strings.TrimSpace(p.Title)
+
+	v := &validator{}
+	v.required("title", p.Title)
+	v.maxLength("title", p.Title, 100)
+	return v.err()
+}
+
+// validateDepartment checks department field rules and that the lead exists
+func (s *OrgService) validateDepartment(ctx context.Context, d *repository.Department) error {
+	d.Name = strings.TrimSpace(d.Name)
+
+	v := &validator{}
+	v.required("name", d.Name)
+	v.maxLength("name", d.Name, 100)
+	v.uuid("lead_id", d.LeadID)
+	if !v.valid() {
+		return v.err()
+	}
+
+	if d.LeadID != "" {
+		if err := checkReference(v, "lead_id", func() error {
+			_, err := s.repos.Employees().GetByID(ctx, d.LeadID)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return v.err()
+}
+
+// validateSite checks site field rules
+func validateSite(site *repository.Site) error {
+	site.Name = strings.TrimSpace(site.Name)
+	site.City = strings.TrimSpace(site.City)
+	site.Address = strings.TrimSpace(site.Address)
+
+	v := &validator{}
+	v.required("name", site.Name)
+	v.maxLength("name", site.Name, 100)
+	v.required("city", site.City)
+	v.maxLength("city", site.City, 100)
+	v.required("address", site.Address)
+	return v.err()
+}
+
+// ensureUnused reports a conflict if any employee still references the record
+func (s *OrgService) ensureUnused(ctx context.Context, kind, column, id string) error {
+	_, total, err := s.repos.Employees().List(ctx, map[string]interface{}{column: id}, 1, 0)
+	if err != nil {
+		return translateError(err)
+	}
+	if total > 0 {
+		return fmt.Errorf("%w: %s %s is assigned to %d employees", ErrConflict, kind, id, total)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// personalFields are the columns holding personal data in the audited
+// tables an employee's own records live in. Assessment entries hold only
+// IDs, statuses and dates, which are kept like the assessments themselves.
+var personalFields = map[string][]string{
+	"employees":                {"first_name", "middle_name", "last_name", "display_name", "email", "address", "profile_picture_url"},
+	"goals":                    {"title", "description"},
+	"goal_checkins":            {"note"},
+	"employee_change_requests": {"changes", "reason", "review_note"},
+}
+
+// SubjectExport bundles everything held about one employee for a data
+// subject access request
+type SubjectExport struct {
+	GeneratedAt         time.Time                           `json:"generated_at"`
+	Employee            *repository.Employee                `json:"employee"`
+	Goals               []*repository.Goal                  `json:"goals"`
+	CheckIns            []*repository.GoalCheckIn           `json:"checkins"`
+	Assessments         []*repository.Assessment            `json:"assessments"`
+	AssessmentsReviewed []*repository.Assessment            `json:"assessments_reviewed"`
+	ChangeRequests      []*repository.EmployeeChangeRequest `json:"change_requests"`
+	AuditLogs           []*repository.AuditLog              `json:"audit_logs"`
+}
+
+// WriteZip writes the export as a ZIP archive with one JSON file per section
+func (e *SubjectExport) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"employee.json", e.Employee},
+		{"goals.json", e.Goals},
+		{"checkins.json", e.CheckIns},
+		{"assessments.json", e.Assessments},
+		{"assessments_reviewed.json", e.AssessmentsReviewed},
+		{"change_requests.json", e.ChangeRequests},
+		{"audit_logs.json", e.AuditLogs},
+	}
+
+	for _, section := range sections {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: e.GeneratedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to export: %w", section.name, err)
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(section.data); err != nil {
+			return fmt.Errorf("failed to write %s to export: %w", section.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// PrivacyService answers data subject access and erasure requests
+type PrivacyService struct {
+	repos    repository.RepositoryFactory
+	profiles *ProfileService
+}
+
+// NewPrivacyService creates a new privacy service. Uploaded profile pictures
+// are deleted through the profile service.
+func NewPrivacyService(repos repository.RepositoryFactory, profiles *ProfileService) *PrivacyService {
+	return &PrivacyService{
+		repos:    repos,
+		profiles: profiles,
+	}
+}
+
+// Export gathers everything held about an employee, including one who has
+// been deleted but not yet purged. The audit history covers the employee's
+// own records and every change they made.
+func (s *PrivacyService) Export(ctx context.Context, employeeID string) (*SubjectExport, error) {
+	ctx, span := startSpan(ctx, "PrivacyService.Export")
+	defer span.End()
+
+	if err := validateID("employee", employeeID); err != nil {
+		return nil, err
+	}
+	export, records, err := s.gather(ctx, s.repos, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recordIDs []string
+	for _, ids := range records {
+		recordIDs = append(recordIDs, ids...)
+	}
+	if export.AuditLogs, err = s.repos.AuditLogs().ListBySubject(ctx, employeeID, recordIDs); err != nil {
+		return nil, translateError(err)
+	}
+
+	return export, nil
+}
+
+// Anonymise erases an employee's personal data from their record, the audit
+// history of their record, goals, check-ins, assessments and change
+// requests, and any uploaded profile picture. Goals, assessments and
+// reporting lines are kept so that aggregate figures stay correct.
+func (s *PrivacyService) Anonymise(ctx context.Context, employeeID string) (err error) {
+	ctx, span := startSpan(ctx, "PrivacyService.Anonymise")
+	defer span.End()
+
+	if err := validateID("employee", employeeID); err != nil {
+		return err
+	}
+
+	tx, err := s.repos.WithTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	export, records, err := s.gather(ctx, tx, employeeID)
+	if err != nil {
+		return err
+	}
+	if err = tx.Employees().Anonymise(ctx, employeeID); err != nil {
+		return translateError(err)
+	}
+	// Redact after anonymising so the audit entry for the update is covered too
+	for table, fields := range personalFields {
+		if len(records[table]) == 0 {
+			continue
+		}
+		if _, err = tx.AuditLogs().RedactFields(ctx, table, records[table], fields); err != nil {
+			return translateError(err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	// The uploaded picture goes once the record no longer refers to it
+	s.profiles.deletePicture(ctx, employeeID, export.Employee.ProfilePicture)
+	return nil
+}
+
+// gather reads everything held about an employee except the audit history,
+// along with the IDs of the employee's own records by table
+func (s *PrivacyService) gather(ctx context.Context, repos repository.RepositoryFactory, employeeID string) (*SubjectExport, map[string][]string, error) {
+	employee, err := repos.Employees().GetByIDIncludingDeleted(ctx, employeeID)
+	if err != nil {
+		return nil, nil, translateError(err)
+	}
+
+	export := &SubjectExport{
+		GeneratedAt: time.Now().UTC(),
+		Employee:    employee,
+		CheckIns:    []*repository.GoalCheckIn{},
+	}
+	records := map[string][]string{"employees": {employee.ID}}
+
+	if export.Goals, err = repos.Goals().GetByEmployee(ctx, employee.ID); err != nil {
+		return nil, nil, translateError(err)
+	}
+	for _, goal := range export.Goals {
+		checkIns, err := repos.Goals().ListCheckIns(ctx, goal.ID)
+		if err != nil {
+			return nil, nil, translateError(err)
+		}
+		export.CheckIns = append(export.CheckIns, checkIns...)
+
+		records["goals"] = append(records["goals"], goal.ID)
+		for _, checkIn := range checkIns {
+			records["goal_checkins"] = append(records["goal_checkins"], checkIn.ID)
+		}
+	}
+
+	if export.Assessments, err = allAssessments(ctx, repos, "employee_id", employee.ID); err != nil {
+		return nil, nil, err
+	}
+	for _, assessment := range export.Assessments {
+		records["assessments"] = append(records["assessments"], assessment.ID)
+	}
+	if export.AssessmentsReviewed, err = allAssessments(ctx, repos, "reviewer_id", employee.ID); err != nil {
+		return nil, nil, err
+	}
+
+	if export.ChangeRequests, err = repos.ChangeRequests().GetByEmployee(ctx, employee.ID); err != nil {
+		return nil, nil, translateError(err)
+	}
+	for _, request := range export.ChangeRequests {
+		records["employee_change_requests"] = append(records["employee_change_requests"], request.ID)
+	}
+
+	return export, records, nil
+}
+
+// allAssessments retrieves every assessment matching a single column filter
+func allAssessments(ctx context.Context, repos repository.RepositoryFactory, column, id string) ([]*repository.Assessment, error) {
+	assessments := []*repository.Assessment{}
+	for offset := 0; ; offset += MaxPageSize {
+		page, total, err := repos.Assessments().List(ctx, map[string]interface{}{column: id}, MaxPageSize, offset)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		assessments = append(assessments, page...)
+		if len(page) == 0 || int64(len(assessments)) >= total {
+			return assessments, nil
+		}
+	}
+}
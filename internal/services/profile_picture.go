@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/images"
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/storage"
+)
+
+// MediaPath is the path under which the server serves stored blobs by key
+const MediaPath = "/media/"
+
+// Profile picture limits. Thumbnails are made in each size, in pixels; the
+// profile picture URL points at the largest, and the others sit next to it
+// named by their size.
+var pictureSizes = []int{512, 128, 48}
+
+const (
+	defaultMaxPictureBytes = 5 << 20
+	maxPicturePixels       = 25_000_000
+)
+
+// errPicturesDisabled is returned for uploads when no blob store is set
+var errPicturesDisabled = errors.New("profile picture uploads are not configured")
+
+// SetPictureStore sets where uploaded profile pictures are stored, and the
+// largest upload accepted in bytes
+func (s *ProfileService) SetPictureStore(blobs storage.BlobStore, maxBytes int64) {
+	s.blobs = blobs
+	if maxBytes > 0 {
+		s.maxPictureBytes = maxBytes
+	}
+}
+
+// UploadPicture makes thumbnails of an uploaded JPEG, PNG, GIF or WebP
+// image, stores them and points the employee's profile picture at them.
+// The previous stored picture is deleted.
+func (s *ProfileService) UploadPicture(ctx context.Context, id string, data []byte, version int) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "ProfileService.UploadPicture")
+	defer span.End()
+
+	if s.blobs == nil {
+		return nil, errPicturesDisabled
+	}
+	if err := requireVersion(version); err != nil {
+		return nil, err
+	}
+	// Check access before spending time on the image
+	employee, err := s.editable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.check(len(data) > 0, "picture", "is required")
+	v.check(int64(len(data)) <= s.maxPictureBytes, "picture", "must be at most "+formatMiB(s.maxPictureBytes))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	thumbnails, err := images.Thumbnails(data, pictureSizes, maxPicturePixels)
+	if err != nil {
+		return nil, pictureError(err)
+	}
+
+	// Pictures are stored under a digest of the upload, so each upload has
+	// new URLs that clients may cache for good
+	digest := sha256.Sum256(data)
+	dir := path.Join("pictures", employee.ID, hex.EncodeToString(digest[:8]))
+	pictureURL := MediaPath + pictureKey(dir, pictureSizes[0])
+
+	stored := pictureURL == employee.ProfilePicture
+	if !stored {
+		for _, size := range pictureSizes {
+			thumbnail := thumbnails[size]
+			if err := s.blobs.Put(ctx, pictureKey(dir, size), bytes.NewReader(thumbnail), int64(len(thumbnail)), images.ContentType); err != nil {
+				s.deletePicture(ctx, employee.ID, pictureURL)
+				return nil, err
+			}
+		}
+	}
+
+	updated, err := s.Update(ctx, id, ProfileUpdate{
+		DisplayName:    employee.DisplayName,
+		Address:        employee.Address,
+		ProfilePicture: pictureURL,
+		Version:        version,
+	})
+	if err != nil {
+		if !stored {
+			s.deletePicture(ctx, employee.ID, pictureURL)
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// RemovePicture clears an employee's profile picture, deleting it if it
+// was uploaded
+func (s *ProfileService) RemovePicture(ctx context.Context, id string, version int) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "ProfileService.RemovePicture")
+	defer span.End()
+
+	if err := requireVersion(version); err != nil {
+		return nil, err
+	}
+	employee, err := s.editable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Update(ctx, id, ProfileUpdate{
+		DisplayName: employee.DisplayName,
+		Address:     employee.Address,
+		Version:     version,
+	})
+}
+
+// deletePicture deletes the thumbnails of a picture uploaded for an
+// employee. URLs pointing elsewhere, including at another employee's
+// pictures, are left alone. Failures are logged rather than returned, since
+// the profile no longer refers to the picture.
+func (s *ProfileService) deletePicture(ctx context.Context, employeeID, pictureURL string) {
+	if s.blobs == nil {
+		return
+	}
+	dir, ok := storedPictureDir(employeeID, pictureURL)
+	if !ok {
+		return
+	}
+	for _, size := range pictureSizes {
+		key := pictureKey(dir, size)
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "Failed to delete profile picture", "key", key, "error", err)
+		}
+	}
+}
+
+// storedPictureDir returns the directory holding the thumbnails of a picture
+// uploaded for an employee, if the URL points at one
+func storedPictureDir(employeeID, pictureURL string) (string, bool) {
+	key, ok := strings.CutPrefix(pictureURL, MediaPath)
+	if !ok || employeeID == "" || storage.ValidateKey(key) != nil {
+		return "", false
+	}
+	dir := path.Dir(key)
+	if path.Dir(dir) != path.Join("pictures", employeeID) || key != pictureKey(dir, pictureSizes[0]) {
+		return "", false
+	}
+	return dir, true
+}
+
+// pictureKey returns the key of a picture's thumbnail of the given size
+func pictureKey(dir string, size int) string {
+	return dir + "/" + strconv.Itoa(size) + ".jpg"
+}
+
+// pictureError reports why an upload could not be made into thumbnails
+func pictureError(err error) error {
+	switch {
+	case errors.Is(err, images.ErrUnsupportedFormat):
+		return NewValidationError("picture", "must be a JPEG, PNG, GIF or WebP image")
+	case errors.Is(err, images.ErrTooManyPixels):
+		return NewValidationError("picture", fmt.Sprintf("must be at most %d megapixels", maxPicturePixels/1_000_000))
+	case errors.Is(err, images.ErrCorrupt):
+		return NewValidationError("picture", "could not be read as an image")
+	}
+	return err
+}
+
+// formatMiB formats a size in bytes as mebibytes
+func formatMiB(n int64) string {
+	return strconv.FormatFloat(float64(n)/(1<<20), 'f', -1, 64) + " MiB"
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gfurduy/byebob/internal/repository"
+	"github.com/gfurduy/byebob/internal/storage"
+)
+
+// Change request statuses
+const (
+	ChangePending  = "pending"
+	ChangeApproved = "approved"
+	ChangeRejected = "rejected"
+)
+
+// Allowed values for change request fields
+var (
+	ChangeRequestStatuses = []string{ChangePending, ChangeApproved, ChangeRejected}
+
+	// SensitiveFields are the employee fields guarded by
+	// restrict_sensitive_fields_update, which only change through an
+	// approved change request
+	SensitiveFields = []string{"employment_type", "start_date", "end_date", "status"}
+)
+
+// dateLayout is the format of dates in change requests
+const dateLayout = "2006-01-02"
+
+// Profile field bounds
+const (
+	maxAddressLength    = 500
+	maxPictureURLLength = 2048
+	maxReasonLength     = 1000
+)
+
+// Profile is an employee with their place in the organisation, as shown on
+// their profile page
+type Profile struct {
+	Employee       *repository.Employee                `json:"employee"`
+	Position       *repository.Position                `json:"position,omitempty"`
+	Department     *repository.Department              `json:"department,omitempty"`
+	Site           *repository.Site                    `json:"site,omitempty"`
+	Managers       []*repository.EmployeeSummary       `json:"managers"`
+	DirectReports  []*repository.Employee              `json:"direct_reports"`
+	ChangeRequests []*repository.EmployeeChangeRequest `json:"change_requests"`
+	Editable       bool                                `json:"editable"`
+}
+
+// PendingChange returns the change request awaiting review, if any
+func (p *Profile) PendingChange() *repository.EmployeeChangeRequest {
+	for _, request := range p.ChangeRequests {
+		if request.Status == ChangePending {
+			return request
+		}
+	}
+	return nil
+}
+
+// ProfileUpdate holds the fields employees maintain themselves
+type ProfileUpdate struct {
+	DisplayName    string
+	Address        string
+	ProfilePicture string
+	Version        int
+}
+
+// ProfileService handles employee profiles: self-service edits and the
+// change requests HR reviews for sensitive fields
+type ProfileService struct {
+	repos           repository.RepositoryFactory
+	blobs           storage.BlobStore
+	maxPictureBytes int64
+}
+
+// NewProfileService creates a new profile service
+func NewProfileService(repos repository.RepositoryFactory) *ProfileService {
+	return &ProfileService{
+		repos:           repos,
+		maxPictureBytes: defaultMaxPictureBytes,
+	}
+}
+
+// Get retrieves the profile of an employee the acting user can see
+func (s *ProfileService) Get(ctx context.Context, id string) (*Profile, error) {
+	ctx, span := startSpan(ctx, "ProfileService.Get")
+	defer span.End()
+
+	if err := validateID("employee", id); err != nil {
+		return nil, err
+	}
+	employee, err := s.repos.Employees().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	profile := &Profile{
+		Employee: employee,
+		Editable: canEdit(ctx, id),
+	}
+	// Deleted positions, departments and sites are left out rather than
+	// failing the whole profile
+	if profile.Position, err = s.repos.Positions().GetByID(ctx, employee.PositionID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, translateError(err)
+	}
+	if profile.Department, err = s.repos.Departments().GetByID(ctx, employee.DepartmentID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, translateError(err)
+	}
+	if profile.Site, err = s.repos.Sites().GetByID(ctx, employee.SiteID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, translateError(err)
+	}
+	if profile.Managers, err = s.repos.Employees().ManagerChain(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	if profile.DirectReports, err = s.repos.Employees().GetByManager(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	if profile.ChangeRequests, err = s.repos.ChangeRequests().GetByEmployee(ctx, id); err != nil {
+		return nil, translateError(err)
+	}
+	return profile, nil
+}
+
+// Update changes the display name, address and picture of an employee. Only
+// the employee themselves and HR may do so.
+func (s *ProfileService) Update(ctx context.Context, id string, update ProfileUpdate) (*repository.Employee, error) {
+	ctx, span := startSpan(ctx, "ProfileService.Update")
+	defer span.End()
+
+	if err := requireVersion(update.Version); err != nil {
+		return nil, err
+	}
+	employee, err := s.editable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousPicture := employee.ProfilePicture
+	employee.DisplayName = strings.TrimSpace(update.DisplayName)
+	employee.Address = strings.TrimSpace(update.Address)
+	employee.ProfilePicture = strings.TrimSpace(update.ProfilePicture)
+	employee.Version = matchVersion(update.Version, employee.Version)
+	if employee.DisplayName == "" {
+		employee.DisplayName = strings.TrimSpace(employee.FirstName + " " + employee.LastName)
+	}
+
+	v := &validator{}
+	v.maxLength("display_name", employee.DisplayName, 200)
+	v.maxLength("address", employee.Address, maxAddressLength)
+	v.maxLength("profile_picture_url", employee.ProfilePicture, maxPictureURLLength)
+	v.check(validPictureURL(employee.ProfilePicture), "profile_picture_url", "must be the URL of a picture uploaded to this server")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.Employees().UpdateProfile(ctx, employee); err != nil {
+		return nil, translateError(err)
+	}
+	if previousPicture != employee.ProfilePicture {
+		s.deletePicture(ctx, employee.ID, previousPicture)
+	}
+	updated, err := s.repos.Employees().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return updated, nil
+}
+
+// RequestChange asks HR to change sensitive fields of an employee. Changes
+// maps field names to their new values; fields already holding the requested
+// value are dropped. An employee has at most one pending request.
+func (s *ProfileService) RequestChange(ctx context.Context, id string, changes map[string]string, reason string) (*repository.EmployeeChangeRequest, error) {
+	ctx, span := startSpan(ctx, "ProfileService.RequestChange")
+	defer span.End()
+
+	employee, err := s.editable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, _ := repository.ActorFromContext(ctx)
+	if actor.EmployeeID == "" {
+		return nil, fmt.Errorf("%w: change requests must be made by an employee", ErrForbidden)
+	}
+
+	reason = strings.TrimSpace(reason)
+	changes, err = validateChanges(employee, changes)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLength("reason", reason, maxReasonLength); err != nil {
+		return nil, err
+	}
+
+	if pending, err := s.pending(ctx, id); err != nil {
+		return nil, err
+	} else if pending != nil {
+		return nil, &FieldConflictError{
+			Kind:   ErrConflict,
+			Detail: fmt.Sprintf("employee %s already has change request %s awaiting review", id, pending.ID),
+			Fields: []FieldError{{Field: "changes", Message: "a change request is already awaiting review"}},
+		}
+	}
+
+	requestID, err := s.repos.ChangeRequests().Create(ctx, &repository.EmployeeChangeRequest{
+		EmployeeID:  id,
+		RequestedBy: actor.EmployeeID,
+		Changes:     changes,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetChangeRequest(ctx, requestID)
+}
+
+// ChangeRequests retrieves the change requests for an employee, newest first
+func (s *ProfileService) ChangeRequests(ctx context.Context, employeeID string) ([]*repository.EmployeeChangeRequest, error) {
+	ctx, span := startSpan(ctx, "ProfileService.ChangeRequests")
+	defer span.End()
+
+	if err := validateID("employee", employeeID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repos.Employees().GetByID(ctx, employeeID); err != nil {
+		return nil, translateError(err)
+	}
+	requests, err := s.repos.ChangeRequests().GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return requests, nil
+}
+
+// GetChangeRequest retrieves a change request by ID
+func (s *ProfileService) GetChangeRequest(ctx context.Context, id string) (*repository.EmployeeChangeRequest, error) {
+	ctx, span := startSpan(ctx, "ProfileService.GetChangeRequest")
+	defer span.End()
+
+	if err := validateID("change request", id); err != nil {
+		return nil, err
+	}
+	request, err := s.repos.ChangeRequests().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return request, nil
+}
+
+// ListChangeRequests retrieves a page of change requests with the given
+// status, or all of them when it is empty, oldest first
+func (s *ProfileService) ListChangeRequests(ctx context.Context, status string, limit, offset int) ([]*repository.EmployeeChangeRequest, int64, error) {
+	ctx, span := startSpan(ctx, "ProfileService.ListChangeRequests")
+	defer span.End()
+
+	v := &validator{}
+	v.oneOf("status", status, ChangeRequestStatuses...)
+	if err := v.err(); err != nil {
+		return nil, 0, err
+	}
+
+	limit, offset = NormalizePage(limit, offset)
+	requests, total, err := s.repos.ChangeRequests().List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	return requests, total, nil
+}
+
+// ApproveChangeRequest applies a pending change request to its employee,
+// provided the result is still a valid employee record
+func (s *ProfileService) ApproveChangeRequest(ctx context.Context, id, note string) (*repository.EmployeeChangeRequest, error) {
+	ctx, span := startSpan(ctx, "ProfileService.ApproveChangeRequest")
+	defer span.End()
+
+	request, err := s.reviewable(ctx, id, note)
+	if err != nil {
+		return nil, err
+	}
+	employee, err := s.repos.Employees().GetByID(ctx, request.EmployeeID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// The employee may have changed since the request was made
+	if _, err := validateChanges(employee, request.Changes); err != nil && !isNoChange(err) {
+		return nil, &FieldConflictError{
+			Kind:   ErrConflict,
+			Detail: fmt.Sprintf("change request %s no longer applies to employee %s: %v", id, employee.ID, err),
+			Fields: FieldErrorsOf(err),
+		}
+	}
+
+	if err := s.repos.ChangeRequests().Approve(ctx, id, employee.Version, strings.TrimSpace(note)); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetChangeRequest(ctx, id)
+}
+
+// RejectChangeRequest closes a pending change request without applying it
+func (s *ProfileService) RejectChangeRequest(ctx context.Context, id, note string) (*repository.EmployeeChangeRequest, error) {
+	ctx, span := startSpan(ctx, "ProfileService.RejectChangeRequest")
+	defer span.End()
+
+	if _, err := s.reviewable(ctx, id, note); err != nil {
+		return nil, err
+	}
+	if err := s.repos.ChangeRequests().Reject(ctx, id, strings.TrimSpace(note)); err != nil {
+		return nil, translateError(err)
+	}
+	return s.GetChangeRequest(ctx, id)
+}
+
+// editable retrieves an employee the acting user may edit: themselves, or
+// anyone when acting for HR
+func (s *ProfileService) editable(ctx context.Context, id string) (*repository.Employee, error) {
+	if err := validateID("employee", id); err != nil {
+		return nil, err
+	}
+	employee, err := s.repos.Employees().GetByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if !canEdit(ctx, id) {
+		return nil, fmt.Errorf("%w: only the employee and HR can edit this profile", ErrForbidden)
+	}
+	return employee, nil
+}
+
+// reviewable retrieves a change request HR can still approve or reject
+func (s *ProfileService) reviewable(ctx context.Context, id, note string) (*repository.EmployeeChangeRequest, error) {
+	if actor, _ := repository.ActorFromContext(ctx); !actor.HR {
+		return nil, fmt.Errorf("%w: only HR can review change requests", ErrForbidden)
+	}
+	if err := validateLength("note", strings.TrimSpace(note), maxReasonLength); err != nil {
+		return nil, err
+	}
+	request, err := s.GetChangeRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != ChangePending {
+		return nil, fmt.Errorf("%w: change request %s is already %s", ErrConflict, id, request.Status)
+	}
+	return request, nil
+}
+
+// pending returns the change request of an employee awaiting review, if any
+func (s *ProfileService) pending(ctx context.Context, employeeID string) (*repository.EmployeeChangeRequest, error) {
+	requests, err := s.repos.ChangeRequests().GetByEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	for _, request := range requests {
+		if request.Status == ChangePending {
+			return request, nil
+		}
+	}
+	return nil, nil
+}
+
+// canEdit reports whether the acting user may edit an employee's profile
+func canEdit(ctx context.Context, employeeID string) bool {
+	actor, _ := repository.ActorFromContext(ctx)
+	return actor.HR || (actor.EmployeeID != "" && actor.EmployeeID == employeeID)
+}
+
+// errNoChange reports a change request that would leave the employee as is
+var errNoChange = NewValidationError("changes", "must change at least one of: "+strings.Join(SensitiveFields, ", "))
+
+// isNoChange reports whether a validation failed only because nothing would
+// change
+func isNoChange(err error) bool {
+	return err == errNoChange
+}
+
+// validateChanges checks requested sensitive field values against the field
+// rules and the employee's current record, returning the changes that differ
+// from it
+func validateChanges(employee *repository.Employee, changes map[string]string) (map[string]string, error) {
+	v := &validator{}
+	startDate, endDate := employee.StartDate, employee.EndDate
+	effective := map[string]string{}
+
+	for field, value := range changes {
+		value = strings.TrimSpace(value)
+		switch field {
+		case "employment_type":
+			v.required(field, value)
+			v.oneOf(field, value, EmploymentTypes...)
+			if value != employee.EmploymentType {
+				effective[field] = value
+			}
+		case "status":
+			v.required(field, value)
+			v.oneOf(field, value, EmployeeStatuses...)
+			if value != employee.Status {
+				effective[field] = value
+			}
+		case "start_date":
+			date, err := time.Parse(dateLayout, value)
+			if err != nil {
+				v.add(field, "must be a date in YYYY-MM-DD format")
+				continue
+			}
+			startDate = date
+			if !date.Equal(employee.StartDate) {
+				effective[field] = value
+			}
+		case "end_date":
+			// An empty end date clears it
+			date := time.Time{}
+			if value != "" {
+				var err error
+				if date, err = time.Parse(dateLayout, value); err != nil {
+					v.add(field, "must be a date in YYYY-MM-DD format")
+					continue
+				}
+			}
+			endDate = date
+			if !date.Equal(employee.EndDate) {
+				effective[field] = value
+			}
+		default:
+			v.add(field, "cannot be changed by request; allowed fields are "+strings.Join(SensitiveFields, ", "))
+		}
+	}
+	v.check(endDate.IsZero() || !endDate.Before(startDate), "end_date", "must not be before start_date")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if len(effective) == 0 {
+		return nil, errNoChange
+	}
+	return effective, nil
+}
+
+// validateLength checks the length of a free-text field
+func validateLength(field, value string, max int) error {
+	v := &validator{}
+	v.maxLength(field, value, max)
+	return v.err()
+}
+
+// validPictureURL reports whether a profile picture URL is empty or points at
+// a blob served from MediaPath. The content security policy only lets pages
+// load images from this server, so an external URL would never display.
+func validPictureURL(value string) bool {
+	if value == "" {
+		return true
+	}
+	key, ok := strings.CutPrefix(value, MediaPath)
+	return ok && storage.ValidateKey(key) == nil
+}
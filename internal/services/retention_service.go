@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gfurduy/byebob/internal/health"
+	"github.com/gfurduy/byebob/internal/metrics"
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// retentionJob names the purge runs in the job metrics
+const retentionJob = "retention_purge"
+
+// DefaultRetention is how long deleted records are kept before they are purged
+const DefaultRetention = 90 * 24 * time.Hour
+
+// PurgeResult counts the records removed by a purge run
+type PurgeResult struct {
+	Employees   int64
+	Positions   int64
+	Departments int64
+	Sites       int64
+}
+
+// Total returns the number of records removed
+func (r PurgeResult) Total() int64 {
+	return r.Employees + r.Positions + r.Departments + r.Sites
+}
+
+// RetentionService permanently removes soft-deleted records once their
+// retention period has passed
+type RetentionService struct {
+	repos     repository.RepositoryFactory
+	retention time.Duration
+	heartbeat health.Heartbeat
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(repos repository.RepositoryFactory, retention time.Duration) *RetentionService {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &RetentionService{
+		repos:     repos,
+		retention: retention,
+	}
+}
+
+// Purge removes records deleted longer ago than the retention period, in
+// every tenant
+func (s *RetentionService) Purge(ctx context.Context) (PurgeResult, error) {
+	ctx, span := startSpan(ctx, "RetentionService.Purge")
+	defer span.End()
+
+	cutoff := time.Now().Add(-s.retention)
+
+	tenants, err := s.repos.Tenants().List(ctx)
+	if err != nil {
+		return PurgeResult{}, translateError(err)
+	}
+
+	var total PurgeResult
+	for _, tenant := range tenants {
+		tenantCtx := repository.ContextWithActor(repository.ContextWithTenant(ctx, tenant.ID), repository.SystemActor)
+		result, err := s.purgeTenant(tenantCtx, cutoff)
+		total.Employees += result.Employees
+		total.Positions += result.Positions
+		total.Departments += result.Departments
+		total.Sites += result.Sites
+		if err != nil {
+			return total, fmt.Errorf("tenant %s: %w", tenant.Slug, err)
+		}
+	}
+	return total, nil
+}
+
+// purgeTenant removes expired records of the tenant carried by the context.
+// Employees go first so the reference data they held can go in the same run.
+func (s *RetentionService) purgeTenant(ctx context.Context, cutoff time.Time) (PurgeResult, error) {
+	var result PurgeResult
+	var err error
+	if result.Employees, err = s.repos.Employees().Purge(ctx, cutoff); err != nil {
+		return result, translateError(err)
+	}
+	if result.Positions, err = s.repos.Positions().Purge(ctx, cutoff); err != nil {
+		return result, translateError(err)
+	}
+	if result.Departments, err = s.repos.Departments().Purge(ctx, cutoff); err != nil {
+		return result, translateError(err)
+	}
+	if result.Sites, err = s.repos.Sites().Purge(ctx, cutoff); err != nil {
+		return result, translateError(err)
+	}
+	return result, nil
+}
+
+// Heartbeat returns the heartbeat of Run, which beats before every purge
+func (s *RetentionService) Heartbeat() *health.Heartbeat {
+	return &s.heartbeat
+}
+
+// Run purges expired records immediately and then at every interval until
+// the context is cancelled
+func (s *RetentionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.heartbeat.Beat(interval)
+		start := time.Now()
+		result, err := s.Purge(ctx)
+		if ctx.Err() == nil {
+			metrics.ObserveJob(retentionJob, time.Since(start), err)
+		}
+		switch {
+		case err != nil && ctx.Err() == nil:
+			slog.ErrorContext(ctx, "Failed to purge deleted records", "error", err)
+		case result.Total() > 0:
+			slog.InfoContext(ctx, "Purged records deleted before the retention period",
+				"employees", result.Employees, "positions", result.Positions,
+				"departments", result.Departments, "sites", result.Sites)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// Search bounds
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+	maxSearchQuery     = 100
+)
+
+// HighlightSegment is a run of a result field, marked when it matched a
+// search term
+type HighlightSegment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// SearchResult is an employee found by a directory search. Highlights split
+// each matched field into segments, so clients can mark matched terms
+// without parsing or escaping HTML.
+type SearchResult struct {
+	*repository.EmployeeSearchResult
+	Highlights map[string][]HighlightSegment `json:"highlights"`
+}
+
+// SearchService handles the employee directory search
+type SearchService struct {
+	repos repository.RepositoryFactory
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(repos repository.RepositoryFactory) *SearchService {
+	return &SearchService{
+		repos: repos,
+	}
+}
+
+// Employees finds the employees best matching a query, among those the acting
+// user is allowed to see
+func (s *SearchService) Employees(ctx context.Context, query string, limit int) ([]*SearchResult, error) {
+	ctx, span := startSpan(ctx, "SearchService.Employees")
+	defer span.End()
+
+	v := &validator{}
+	v.required("q", query)
+	v.maxLength("q", query, maxSearchQuery)
+	v.check(limit > 0 && limit <= MaxSearchLimit, "limit", fmt.Sprintf("must be between 1 and %d", MaxSearchLimit))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	terms := repository.SearchTerms(query)
+	if len(terms) == 0 {
+		return []*SearchResult{}, nil
+	}
+
+	found, err := s.repos.Employees().Search(ctx, query, limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	results := make([]*SearchResult, len(found))
+	for i, employee := range found {
+		results[i] = &SearchResult{
+			EmployeeSearchResult: employee,
+			Highlights: map[string][]HighlightSegment{
+				"display_name":    highlight(employee.DisplayName, terms),
+				"email":           highlight(employee.Email, terms),
+				"position_title":  highlight(employee.PositionTitle, terms),
+				"department_name": highlight(employee.DepartmentName, terms),
+				"site_name":       highlight(employee.SiteName, terms),
+			},
+		}
+	}
+	return results, nil
+}
+
+// highlight splits text into segments, marking the start of each word that
+// begins with a search term. Fields matched only by similarity, such as a
+// misspelt name, come back as a single unmarked segment.
+func highlight(text string, terms []string) []HighlightSegment {
+	if text == "" {
+		return nil
+	}
+
+	var segments []HighlightSegment
+	start := 0
+	prevWord := false
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if word && !prevWord {
+			if n := matchedPrefix(text[i:], terms); n > 0 {
+				if start < i {
+					segments = append(segments, HighlightSegment{Text: text[start:i]})
+				}
+				segments = append(segments, HighlightSegment{Text: text[i : i+n], Match: true})
+				start = i + n
+			}
+		}
+		prevWord = word
+	}
+	if start < len(text) {
+		segments = append(segments, HighlightSegment{Text: text[start:]})
+	}
+	return segments
+}
+
+// matchedPrefix returns the length in bytes of the longest term that text
+// starts with, ignoring case, or zero when none does
+func matchedPrefix(text string, terms []string) int {
+	longest := 0
+	for _, term := range terms {
+		if n := foldPrefix(text, term); n > longest {
+			longest = n
+		}
+	}
+	return longest
+}
+
+// foldPrefix returns how many bytes of text match the lower-case prefix,
+// ignoring case, or zero when text does not start with it
+func foldPrefix(text, prefix string) int {
+	n := 0
+	for _, want := range prefix {
+		r, size := utf8.DecodeRuneInString(text[n:])
+		if size == 0 || unicode.ToLower(r) != want {
+			return 0
+		}
+		n += size
+	}
+	return n
+}
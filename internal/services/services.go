@@ -2,31 +2,44 @@ package services
 
 import (
 	"context"
-	
-	"github.com/gfurduy/byebob/internal/models"
+
 	"github.com/gfurduy/byebob/internal/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// EmployeeService handles employee business logic
-type EmployeeService struct {
-	repo repository.Repository
-}
+// tracerName identifies the spans started for service methods
+const tracerName = "github.com/gfurduy/byebob/internal/services"
 
-// NewEmployeeService creates a new employee service
-func NewEmployeeService(repo repository.Repository) *EmployeeService {
-	return &EmployeeService{
-		repo: repo,
-	}
+// Services groups the application services that share a repository factory
+type Services struct {
+	Tenants     *TenantService
+	Employees   *EmployeeService
+	Org         *OrgService
+	Assessments *AssessmentService
+	Goals       *GoalService
+	Privacy     *PrivacyService
+	Search      *SearchService
+	Profiles    *ProfileService
 }
 
-// GetEmployees retrieves all employees
-func (s *EmployeeService) GetEmployees(ctx context.Context) ([]models.Employee, error) {
-	return s.repo.GetEmployees(ctx)
+// New creates all application services backed by the given repository factory
+func New(repos repository.RepositoryFactory) *Services {
+	profiles := NewProfileService(repos)
+	return &Services{
+		Tenants:     NewTenantService(repos),
+		Employees:   NewEmployeeService(repos),
+		Org:         NewOrgService(repos),
+		Assessments: NewAssessmentService(repos),
+		Goals:       NewGoalService(repos),
+		Privacy:     NewPrivacyService(repos, profiles),
+		Search:      NewSearchService(repos),
+		Profiles:    profiles,
+	}
 }
 
-// GetEmployeeByID retrieves an employee by ID
-func (s *EmployeeService) GetEmployeeByID(ctx context.Context, id string) (models.Employee, error) {
-	return s.repo.GetEmployeeByID(ctx, id)
+// startSpan starts the span of a service method under the span carried by
+// the context
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name)
 }
-
-// Other service implementations will be added here as the project progresses 
\ No newline at end of file
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gfurduy/byebob/internal/repository"
+)
+
+// TenantService resolves which tenant a request belongs to
+type TenantService struct {
+	repos       repository.RepositoryFactory
+	defaultSlug string
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(repos repository.RepositoryFactory) *TenantService {
+	return &TenantService{
+		repos: repos,
+	}
+}
+
+// SetDefaultTenant sets the tenant used when a request does not identify one.
+// An empty slug makes identifying the tenant mandatory.
+func (s *TenantService) SetDefaultTenant(slug string) {
+	s.defaultSlug = slug
+}
+
+// Resolve finds the tenant for a request, first by the hostname it was sent
+// to and then by the tenant slug the acting user's identity names
+func (s *TenantService) Resolve(ctx context.Context, host, slug string) (*repository.Tenant, error) {
+	ctx, span := startSpan(ctx, "TenantService.Resolve")
+	defer span.End()
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if host != "" {
+		tenant, err := s.repos.Tenants().GetByHostname(ctx, host)
+		if err == nil {
+			return tenant, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, translateError(err)
+		}
+	}
+
+	if slug == "" {
+		slug = s.defaultSlug
+	}
+	if slug == "" {
+		return nil, fmt.Errorf("%w: no tenant is served on %s", ErrNotFound, host)
+	}
+	tenant, err := s.repos.Tenants().GetBySlug(ctx, strings.ToLower(slug))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tenant, nil
+}
+
+// CheckMember checks that an acting user whose identity names the given
+// tenant slug may act in a tenant. Identities naming no tenant belong to the
+// default tenant.
+func (s *TenantService) CheckMember(tenant *repository.Tenant, slug string) error {
+	if slug == "" {
+		slug = s.defaultSlug
+	}
+	if !strings.EqualFold(tenant.Slug, slug) {
+		return fmt.Errorf("%w: the acting user does not belong to tenant %s", ErrForbidden, tenant.Slug)
+	}
+	return nil
+}
+
+// List retrieves all tenants
+func (s *TenantService) List(ctx context.Context) ([]*repository.Tenant, error) {
+	ctx, span := startSpan(ctx, "TenantService.List")
+	defer span.End()
+
+	tenants, err := s.repos.Tenants().List(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tenants, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pagination bounds applied to list operations
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validator accumulates field errors so that all problems are reported at once
+type validator struct {
+	fields []FieldError
+}
+
+// add records a validation failure for a field
+func (v *validator) add(field, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+// check records a failure when ok is false
+func (v *validator) check(ok bool, field, message string) {
+	if !ok {
+		v.add(field, message)
+	}
+}
+
+// required checks that a string field is not blank
+func (v *validator) required(field, value string) {
+	v.check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// maxLength checks that a string field does not exceed the column size
+func (v *validator) maxLength(field, value string, max int) {
+	v.check(utf8.RuneCountInString(value) <= max, field, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// email checks that a non-empty field is a bare email address
+func (v *validator) email(field, value string) {
+	if value == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	v.check(err == nil && addr.Address == value, field, "must be a valid email address")
+}
+
+// uuid checks that a non-empty field is a UUID
+func (v *validator) uuid(field, value string) {
+	if value == "" {
+		return
+	}
+	v.check(uuidPattern.MatchString(value), field, "must be a valid UUID")
+}
+
+// oneOf checks that a non-empty field is one of the allowed values
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	v.check(slices.Contains(allowed, value), field, "must be one of: "+strings.Join(allowed, ", "))
+}
+
+// valid reports whether no failures have been recorded
+func (v *validator) valid() bool {
+	return len(v.fields) == 0
+}
+
+// err returns a ValidationError if any failures were recorded
+func (v *validator) err() error {
+	if v.valid() {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+// NormalizePage clamps limit and offset to sane values
+func NormalizePage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// validateID returns a not-found error for identifiers that cannot exist
+func validateID(kind, id string) error {
+	if !uuidPattern.MatchString(id) {
+		return fmt.Errorf("%w: %s %s", ErrNotFound, kind, id)
+	}
+	return nil
+}
+
+// AnyVersion asks for an update regardless of the current version, as
+// If-Match: * does
+const AnyVersion = -1
+
+// requireVersion checks that an update names the version it was based on,
+// or AnyVersion
+func requireVersion(version int) error {
+	if version <= 0 && version != AnyVersion {
+		return NewValidationError("version", "is required for updates")
+	}
+	return nil
+}
+
+// matchVersion returns the version an update must match: the one it names,
+// or the current one for AnyVersion
+func matchVersion(version, current int) int {
+	if version == AnyVersion {
+		return current
+	}
+	return version
+}
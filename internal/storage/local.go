@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalStore keeps blobs as files under a root directory
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store under root, creating the directory if it
+// does not exist
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &LocalStore{
+		root: root,
+	}, nil
+}
+
+// Put writes a blob to a temporary file and renames it into place, so
+// readers never see a partial blob
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if err == nil && written != size {
+		err = fmt.Errorf("wrote %d bytes, expected %d", written, size)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens a blob file. The content type is derived from the key's
+// extension.
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, nil, fmt.Errorf("failed to open blob %s: %w", key, err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to open blob %s: %w", key, err)
+	}
+	if stat.IsDir() {
+		f.Close()
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return f, &BlobInfo{
+		ContentType: contentType,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+	}, nil
+}
+
+// Delete removes a blob file
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// Check reports whether the root directory still exists
+func (s *LocalStore) Check(ctx context.Context) error {
+	stat, err := os.Stat(s.root)
+	if err != nil {
+		return fmt.Errorf("blob directory unavailable: %w", err)
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("blob directory %s is not a directory", s.root)
+	}
+	return nil
+}
+
+// path returns the file holding the blob stored under key
+func (s *LocalStore) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
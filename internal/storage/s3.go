@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config locates an S3-compatible bucket, on AWS or a self-hosted server
+// such as MinIO
+type S3Config struct {
+	// Endpoint is the server's base URL, such as https://s3.eu-west-1.amazonaws.com
+	// or http://localhost:9000; plain http is used only when it says so
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store keeps blobs as objects in an S3-compatible bucket
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store creates a store for an existing bucket. No request is made
+// until the store is used.
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: must be an http(s) URL", cfg.Endpoint)
+	}
+
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: endpoint.Scheme == "https",
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return &S3Store{
+		client: client,
+		bucket: cfg.Bucket,
+	}, nil
+}
+
+// Put uploads a blob as an object
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens an object for reading
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+	// The request is only made when the object is first read or stat'ed
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if isNoSuchKey(err) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+
+	return object, &BlobInfo{
+		ContentType: stat.ContentType,
+		Size:        stat.Size,
+		ModTime:     stat.LastModified,
+	}, nil
+}
+
+// Delete removes an object. S3 reports success for missing objects.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil && !isNoSuchKey(err) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// Check reports whether the bucket exists and the credentials can reach it
+func (s *S3Store) Check(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("S3 bucket unavailable: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("S3 bucket %s does not exist", s.bucket)
+	}
+	return nil
+}
+
+// isNoSuchKey reports whether an S3 error says the object does not exist
+func isNoSuchKey(err error) bool {
+	var resp minio.ErrorResponse
+	return errors.As(err, &resp) && resp.Code == "NoSuchKey"
+}
@@ -0,0 +1,74 @@
+// Package storage keeps binary objects, such as processed profile pictures,
+// in a BlobStore: a directory on the local filesystem or a bucket of an
+// S3-compatible object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gfurduy/byebob/config"
+)
+
+// ErrNotFound is returned when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned for keys that are empty, absolute or that
+// contain empty, "." or ".." segments
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// BlobStore stores blobs under slash-separated keys such as
+// "pictures/<employee>/<digest>/128.jpg"
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing any blob
+	// stored there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the blob stored under key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+
+	// Delete removes the blob stored under key. Deleting a missing blob is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Check reports whether the store can be reached, for readiness checks
+	Check(ctx context.Context) error
+}
+
+// ValidateKey checks that a key is a relative, slash-separated path that
+// cannot escape the store
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsRune(key, '\\') {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// Open opens the blob store selected by the configuration
+func Open(cfg *config.Config) (BlobStore, error) {
+	if cfg.BlobStore == "s3" {
+		return NewS3Store(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	}
+	return NewLocalStore(cfg.BlobDir)
+}
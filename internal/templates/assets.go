@@ -0,0 +1,9 @@
+package templates
+
+import "github.com/gfurduy/byebob/static"
+
+// Asset returns the fingerprinted URL of a static asset, such as
+// Asset("css/main.css")
+func Asset(path string) string {
+	return static.URL(path)
+}
@@ -0,0 +1,30 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// csrfHeader is the header the CSRF middleware reads the token from
+const csrfHeader = "X-CSRF-Token"
+
+// csrfTokenKey is the context key carrying the CSRF token of the request
+type csrfTokenKey struct{}
+
+// WithCSRFToken returns a context whose pages send the CSRF token with their
+// htmx requests
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey{}, token)
+}
+
+// csrfToken returns the CSRF token carried by the context
+func csrfToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// csrfHeaders returns the hx-headers value sending the CSRF token
+func csrfHeaders(ctx context.Context) string {
+	headers, _ := json.Marshal(map[string]string{csrfHeader: csrfToken(ctx)})
+	return string(headers)
+}
@@ -0,0 +1,163 @@
+package templates
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gfurduy/byebob/internal/services"
+)
+
+// dateLayout is the format of dates shown and entered on pages
+const dateLayout = "2006-01-02"
+
+// Notice is the outcome of a form submission shown above the form
+type Notice struct {
+	Success string
+	Error   string
+	Fields  []services.FieldError
+}
+
+// ProfileView is what the profile page renders
+type ProfileView struct {
+	Profile *services.Profile
+
+	// Reviewer is set when the viewer is in HR and may review change requests
+	Reviewer bool
+
+	Notice Notice
+}
+
+// profileURL returns the profile page of an employee
+func profileURL(id string) templ.SafeURL {
+	return templ.URL("/profile/" + id)
+}
+
+// profileAction returns the URL a profile form posts to
+func profileAction(id string, path ...string) string {
+	url := "/profile/" + id
+	for _, part := range path {
+		url += "/" + part
+	}
+	return url
+}
+
+// formatDate formats an optional date
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+// orDash shows a placeholder for an empty value
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
+
+// version formats a record version for a hidden form field
+func version(v int) string {
+	return strconv.Itoa(v)
+}
+
+// changeFields lists the fields of a change request in a stable order
+func changeFields(changes map[string]string) []string {
+	var fields []string
+	for _, field := range services.SensitiveFields {
+		if _, ok := changes[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// pictureAccept lists the image types the picture upload accepts
+const pictureAccept = "image/jpeg,image/png,image/gif,image/webp"
+
+// fieldLabels name the profile form fields for people
+var fieldLabels = map[string]string{
+	"employment_type":     "Employment type",
+	"start_date":          "Start date",
+	"end_date":            "End date",
+	"status":              "Status",
+	"display_name":        "Display name",
+	"address":             "Address",
+	"profile_picture_url": "Profile picture URL",
+	"picture":             "Picture",
+	"reason":              "Reason",
+	"note":                "Note",
+	"changes":             "Changes",
+}
+
+// fieldLabel names a profile form field for people
+func fieldLabel(field string) string {
+	if label, ok := fieldLabels[field]; ok {
+		return label
+	}
+	return field
+}
+
+// currentValue returns the value a sensitive field of the profile's employee
+// holds now
+func currentValue(p *services.Profile, field string) string {
+	switch field {
+	case "employment_type":
+		return p.Employee.EmploymentType
+	case "start_date":
+		return formatDate(p.Employee.StartDate)
+	case "end_date":
+		return formatDate(p.Employee.EndDate)
+	case "status":
+		return p.Employee.Status
+	}
+	return ""
+}
+
+// fieldError returns the messages reported for a form field
+func fieldError(n Notice, field string) string {
+	message := ""
+	for _, f := range n.Fields {
+		if f.Field == field {
+			if message != "" {
+				message += "; "
+			}
+			message += f.Message
+		}
+	}
+	return message
+}
+
+// positionTitle returns the title of the employee's position, if it exists
+func positionTitle(p *services.Profile) string {
+	if p.Position == nil {
+		return ""
+	}
+	return p.Position.Title
+}
+
+// departmentName returns the name of the employee's department, if it exists
+func departmentName(p *services.Profile) string {
+	if p.Department == nil {
+		return ""
+	}
+	return p.Department.Name
+}
+
+// siteName returns the name and city of the employee's site, if it exists
+func siteName(p *services.Profile) string {
+	if p.Site == nil {
+		return ""
+	}
+	if p.Site.City == "" {
+		return p.Site.Name
+	}
+	return p.Site.Name + ", " + p.Site.City
+}
+
+// pictureURL sanitises a profile picture URL for use as an image source
+func pictureURL(url string) templ.SafeURL {
+	return templ.URL(url)
+}
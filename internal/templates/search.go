@@ -0,0 +1,15 @@
+package templates
+
+import "github.com/gfurduy/byebob/internal/services"
+
+// organisation returns the highlighted position, department and site of a
+// search result, skipping those the employee has none of
+func organisation(result *services.SearchResult) [][]services.HighlightSegment {
+	var fields [][]services.HighlightSegment
+	for _, key := range []string{"position_title", "department_name", "site_name"} {
+		if segments := result.Highlights[key]; len(segments) > 0 {
+			fields = append(fields, segments)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,13 @@
+// Package migrations embeds the SQL migrations, so every binary carries the
+// schema that matches its code
+package migrations
+
+import "embed"
+
+// PostgresDir is the directory of the Postgres migrations within FS
+const PostgresDir = "postgres"
+
+// FS holds the Postgres migrations
+//
+//go:embed postgres/*.sql
+var FS embed.FS
@@ -15,17 +15,7 @@ DROP POLICY IF EXISTS employees_app_policy ON employees;
 DROP POLICY IF EXISTS employees_admin_policy ON employees;
 DROP POLICY IF EXISTS employees_readonly_policy ON employees;
 
--- Revoke roles from users
-REVOKE byebob_app_role FROM byebob_app;
-REVOKE byebob_admin_role FROM byebob_admin;
-REVOKE byebob_readonly_role FROM byebob_readonly;
-
--- Drop users
-DROP USER IF EXISTS byebob_app;
-DROP USER IF EXISTS byebob_admin;
-DROP USER IF EXISTS byebob_readonly;
-
--- Drop roles
+-- Drop roles; their login users belong to "byebob db bootstrap" and are kept
 DROP ROLE IF EXISTS byebob_app_role;
 DROP ROLE IF EXISTS byebob_admin_role;
 DROP ROLE IF EXISTS byebob_readonly_role;
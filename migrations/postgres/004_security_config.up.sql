@@ -3,10 +3,19 @@
 
 BEGIN;
 
--- Create application roles
-CREATE ROLE byebob_app_role;
-CREATE ROLE byebob_admin_role;
-CREATE ROLE byebob_readonly_role;
+-- Create application roles unless "byebob db bootstrap" already has. Login
+-- users and their passwords are provisioned by that command, never here.
+DO $$
+DECLARE
+    role_name TEXT;
+BEGIN
+    FOREACH role_name IN ARRAY ARRAY['byebob_app_role', 'byebob_admin_role', 'byebob_readonly_role'] LOOP
+        IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = role_name) THEN
+            EXECUTE format('CREATE ROLE %I NOLOGIN', role_name);
+        END IF;
+    END LOOP;
+END
+$$;
 
 -- Grant privileges to app role (service account)
 GRANT USAGE ON SCHEMA public TO byebob_app_role;
@@ -29,16 +38,6 @@ GRANT USAGE ON SCHEMA public TO byebob_readonly_role;
 GRANT SELECT ON ALL TABLES IN SCHEMA public TO byebob_readonly_role;
 GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO byebob_readonly_role;
 
--- Create application users
-CREATE USER byebob_app WITH PASSWORD 'app_password_placeholder';
-CREATE USER byebob_admin WITH PASSWORD 'admin_password_placeholder';
-CREATE USER byebob_readonly WITH PASSWORD 'readonly_password_placeholder';
-
--- Assign roles to users
-GRANT byebob_app_role TO byebob_app;
-GRANT byebob_admin_role TO byebob_admin;
-GRANT byebob_readonly_role TO byebob_readonly;
-
 -- Set default privileges for future objects
 ALTER DEFAULT PRIVILEGES IN SCHEMA public
     GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO byebob_app_role;
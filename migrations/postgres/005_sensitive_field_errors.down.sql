@@ -0,0 +1,27 @@
+-- Migration: sensitive_field_errors (down)
+-- Created at: 2025-05-22T09:00:00Z
+
+BEGIN;
+
+-- Restore the original trigger function from 004_security_config
+CREATE OR REPLACE FUNCTION restrict_sensitive_fields_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Check if the current user has the app role but not admin role
+    IF (SELECT pg_has_role(CURRENT_USER, 'byebob_app_role', 'MEMBER') AND 
+        NOT pg_has_role(CURRENT_USER, 'byebob_admin_role', 'MEMBER')) THEN
+        
+        -- Check if sensitive fields were modified
+        IF (OLD.employment_type IS DISTINCT FROM NEW.employment_type) OR
+           (OLD.start_date IS DISTINCT FROM NEW.start_date) OR
+           (OLD.end_date IS DISTINCT FROM NEW.end_date) OR
+           (OLD.status IS DISTINCT FROM NEW.status) THEN
+            RAISE EXCEPTION 'Not authorized to modify sensitive employee fields';
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
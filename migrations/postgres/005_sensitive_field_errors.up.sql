@@ -0,0 +1,40 @@
+-- Migration: sensitive_field_errors (up)
+-- Created at: 2025-05-22T09:00:00Z
+
+BEGIN;
+
+-- Report which sensitive field was modified so the application can surface it.
+-- The error uses SQLSTATE 42501 (insufficient_privilege) and sets COLUMN.
+CREATE OR REPLACE FUNCTION restrict_sensitive_fields_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed_field TEXT;
+BEGIN
+    -- Check if the current user has the app role but not admin role
+    IF (SELECT pg_has_role(CURRENT_USER, 'byebob_app_role', 'MEMBER') AND 
+        NOT pg_has_role(CURRENT_USER, 'byebob_admin_role', 'MEMBER')) THEN
+        
+        -- Find the first sensitive field that was modified
+        IF OLD.employment_type IS DISTINCT FROM NEW.employment_type THEN
+            changed_field := 'employment_type';
+        ELSIF OLD.start_date IS DISTINCT FROM NEW.start_date THEN
+            changed_field := 'start_date';
+        ELSIF OLD.end_date IS DISTINCT FROM NEW.end_date THEN
+            changed_field := 'end_date';
+        ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+            changed_field := 'status';
+        END IF;
+
+        IF changed_field IS NOT NULL THEN
+            RAISE EXCEPTION 'Not authorized to modify sensitive employee fields'
+                USING ERRCODE = 'insufficient_privilege',
+                      COLUMN = changed_field,
+                      TABLE = TG_TABLE_NAME;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- Migration: row_versions (down)
+-- Created at: 2025-05-22T10:00:00Z
+
+BEGIN;
+
+-- Remove row version columns
+ALTER TABLE goals DROP COLUMN IF EXISTS version;
+ALTER TABLE assessments DROP COLUMN IF EXISTS version;
+ALTER TABLE sites DROP COLUMN IF EXISTS version;
+ALTER TABLE departments DROP COLUMN IF EXISTS version;
+ALTER TABLE positions DROP COLUMN IF EXISTS version;
+ALTER TABLE employees DROP COLUMN IF EXISTS version;
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- Migration: row_versions (up)
+-- Created at: 2025-05-22T10:00:00Z
+
+BEGIN;
+
+-- Add row versions for optimistic concurrency control.
+-- Every UPDATE issued by the application increments the version and only
+-- succeeds if the version the client last read is still current.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE sites ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE assessments ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMIT;
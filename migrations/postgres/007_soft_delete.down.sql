@@ -0,0 +1,22 @@
+-- Migration: soft_delete (down)
+-- Created at: 2025-05-23T10:00:00Z
+
+BEGIN;
+
+-- Restore the table-wide email constraint. This fails if a deleted and an
+-- active employee share an email address.
+DROP INDEX IF EXISTS employees_email_key;
+ALTER TABLE employees ADD CONSTRAINT employees_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_sites_deleted_at;
+DROP INDEX IF EXISTS idx_departments_deleted_at;
+DROP INDEX IF EXISTS idx_positions_deleted_at;
+DROP INDEX IF EXISTS idx_employees_deleted_at;
+
+-- Soft-deleted records become visible again
+ALTER TABLE sites DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE departments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE positions DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE employees DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
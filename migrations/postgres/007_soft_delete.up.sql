@@ -0,0 +1,25 @@
+-- Migration: soft_delete (up)
+-- Created at: 2025-05-23T10:00:00Z
+
+BEGIN;
+
+-- Deleting employees and reference data only marks them as deleted so that
+-- assessments, goals and audit history keep pointing at real rows. Records
+-- are purged for good once the retention period has passed.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE sites ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Support the purge job's scan for expired records
+CREATE INDEX idx_employees_deleted_at ON employees(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_positions_deleted_at ON positions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_departments_deleted_at ON departments(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_sites_deleted_at ON sites(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Email addresses only need to be unique among employees that are not deleted,
+-- so a returning employee can be created again
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_email_key;
+CREATE UNIQUE INDEX employees_email_key ON employees(email) WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,47 @@
+-- Migration: field_encryption (down)
+-- Created at: 2025-05-24T10:00:00Z
+
+BEGIN;
+
+-- Restore the original audit function. Values already encrypted by the
+-- application stay encrypted, and redacted audit entries stay redacted.
+CREATE OR REPLACE FUNCTION audit_log_func() RETURNS TRIGGER AS $$
+DECLARE
+    changes_json JSONB;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        changes_json = to_jsonb(OLD);
+        INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+        VALUES (current_setting('app.user_id', TRUE)::UUID, 'DELETE', TG_TABLE_NAME, OLD.id, changes_json);
+        RETURN OLD;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        changes_json = jsonb_object_agg(key, value)
+        FROM (
+            SELECT key, value
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+        ) AS changed_fields;
+
+        IF changes_json IS NOT NULL AND changes_json <> '{}'::JSONB THEN
+            INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+            VALUES (current_setting('app.user_id', TRUE)::UUID, 'UPDATE', TG_TABLE_NAME, NEW.id, changes_json);
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'INSERT') THEN
+        changes_json = to_jsonb(NEW);
+        INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+        VALUES (current_setting('app.user_id', TRUE)::UUID, 'INSERT', TG_TABLE_NAME, NEW.id, changes_json);
+        RETURN NEW;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS audit_redact(TEXT, JSONB);
+DROP TABLE IF EXISTS audit_redacted_columns;
+
+DROP INDEX IF EXISTS idx_employees_address_bidx;
+ALTER TABLE employees DROP COLUMN IF EXISTS address_bidx;
+
+COMMIT;
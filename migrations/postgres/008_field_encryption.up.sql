@@ -0,0 +1,79 @@
+-- Migration: field_encryption (up)
+-- Created at: 2025-05-24T10:00:00Z
+
+BEGIN;
+
+-- Encrypted columns are written by the application as envelopes. A blind
+-- index (keyed hash of the normalised value) supports exact-match lookups.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS address_bidx TEXT;
+CREATE INDEX idx_employees_address_bidx ON employees(address_bidx);
+
+-- Columns whose values are never copied into audit_logs.changes. The audit
+-- entry still records that the column changed.
+CREATE TABLE IF NOT EXISTS audit_redacted_columns (
+    table_name VARCHAR(100) NOT NULL,
+    column_name VARCHAR(100) NOT NULL,
+    PRIMARY KEY (table_name, column_name)
+);
+
+INSERT INTO audit_redacted_columns (table_name, column_name) VALUES
+    ('employees', 'address'),
+    ('employees', 'address_bidx')
+ON CONFLICT DO NOTHING;
+
+-- Replace the values of redacted columns in a changes document
+CREATE OR REPLACE FUNCTION audit_redact(tbl TEXT, changes JSONB) RETURNS JSONB AS $$
+    SELECT CASE WHEN changes IS NULL THEN NULL ELSE (
+        SELECT COALESCE(jsonb_object_agg(
+            fields.key,
+            CASE
+                WHEN redacted.column_name IS NULL OR fields.value = 'null'::JSONB THEN fields.value
+                ELSE to_jsonb('[redacted]'::TEXT)
+            END
+        ), '{}'::JSONB)
+        FROM jsonb_each(changes) AS fields(key, value)
+        LEFT JOIN audit_redacted_columns redacted
+            ON redacted.table_name = tbl AND redacted.column_name = fields.key
+    ) END
+$$ LANGUAGE sql STABLE;
+
+-- Record audit logs with redacted columns scrubbed
+CREATE OR REPLACE FUNCTION audit_log_func() RETURNS TRIGGER AS $$
+DECLARE
+    changes_json JSONB;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        changes_json = to_jsonb(OLD);
+        INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+        VALUES (current_setting('app.user_id', TRUE)::UUID, 'DELETE', TG_TABLE_NAME, OLD.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN OLD;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        changes_json = jsonb_object_agg(key, value)
+        FROM (
+            SELECT key, value
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+        ) AS changed_fields;
+
+        IF changes_json IS NOT NULL AND changes_json <> '{}'::JSONB THEN
+            INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+            VALUES (current_setting('app.user_id', TRUE)::UUID, 'UPDATE', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'INSERT') THEN
+        changes_json = to_jsonb(NEW);
+        INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+        VALUES (current_setting('app.user_id', TRUE)::UUID, 'INSERT', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN NEW;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Scrub values already copied into the audit log
+UPDATE audit_logs
+SET changes = audit_redact(table_name, changes)
+WHERE table_name IN (SELECT table_name FROM audit_redacted_columns);
+
+COMMIT;
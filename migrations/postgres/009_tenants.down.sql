@@ -0,0 +1,67 @@
+-- Migration: tenants (down)
+-- Created at: 2025-05-25T10:00:00Z
+
+BEGIN;
+
+-- Restore the audit function from 008_field_encryption
+CREATE OR REPLACE FUNCTION audit_log_func() RETURNS TRIGGER AS $$
+DECLARE
+    changes_json JSONB;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        changes_json = to_jsonb(OLD);
+        INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+        VALUES (current_setting('app.user_id', TRUE)::UUID, 'DELETE', TG_TABLE_NAME, OLD.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN OLD;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        changes_json = jsonb_object_agg(key, value)
+        FROM (
+            SELECT key, value
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+        ) AS changed_fields;
+
+        IF changes_json IS NOT NULL AND changes_json <> '{}'::JSONB THEN
+            INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+            VALUES (current_setting('app.user_id', TRUE)::UUID, 'UPDATE', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'INSERT') THEN
+        changes_json = to_jsonb(NEW);
+        INSERT INTO audit_logs (user_id, action, table_name, record_id, changes)
+        VALUES (current_setting('app.user_id', TRUE)::UUID, 'INSERT', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN NEW;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Restore the email index from 007_soft_delete. This fails if two tenants
+-- share an active email address.
+DROP INDEX IF EXISTS employees_email_key;
+CREATE UNIQUE INDEX employees_email_key ON employees(email) WHERE deleted_at IS NULL;
+
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'employees', 'positions', 'departments', 'sites',
+        'assessment_templates', 'assessments', 'goals', 'goal_checkins', 'audit_logs'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS %s_tenant_isolation ON %I', tbl, tbl);
+        IF tbl <> 'employees' THEN
+            EXECUTE format('DROP POLICY IF EXISTS %s_access ON %I', tbl, tbl);
+            EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', tbl);
+            EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', tbl);
+        END IF;
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS tenant_id', tbl);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION IF EXISTS current_tenant_id();
+DROP TABLE IF EXISTS tenants;
+
+COMMIT;
@@ -0,0 +1,99 @@
+-- Migration: tenants (up)
+-- Created at: 2025-05-25T10:00:00Z
+
+BEGIN;
+
+-- Each subsidiary is a tenant sharing one deployment
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(200) NOT NULL,
+    hostname VARCHAR(255) UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Existing data belongs to the default tenant
+INSERT INTO tenants (id, slug, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Default')
+ON CONFLICT DO NOTHING;
+
+-- The tenant of the current transaction, set by the application with
+-- set_config('app.tenant_id', ..., true). NULL when unset, so policies fail closed.
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', TRUE), '')::UUID
+$$ LANGUAGE sql STABLE;
+
+-- Add tenant_id to every tenant-owned table. Existing rows take the default
+-- tenant; new rows take the tenant of the current transaction.
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'employees', 'positions', 'departments', 'sites',
+        'assessment_templates', 'assessments', 'goals', 'goal_checkins', 'audit_logs'
+    ] LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL
+                DEFAULT ''00000000-0000-0000-0000-000000000001''
+                CONSTRAINT fk_%s_tenant REFERENCES tenants(id)', tbl, tbl);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET DEFAULT current_tenant_id()', tbl);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS idx_%s_tenant_id ON %I(tenant_id)', tbl, tbl);
+
+        -- Tenant isolation is restrictive, so it applies on top of any
+        -- permissive policy, including the role policies on employees
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tbl);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', tbl);
+        EXECUTE format(
+            'CREATE POLICY %s_tenant_isolation ON %I AS RESTRICTIVE FOR ALL
+                USING (tenant_id = current_tenant_id())
+                WITH CHECK (tenant_id = current_tenant_id())', tbl, tbl);
+
+        -- Employees already have role policies from 004_security_config
+        IF tbl <> 'employees' THEN
+            EXECUTE format('CREATE POLICY %s_access ON %I FOR ALL USING (true)', tbl, tbl);
+        END IF;
+    END LOOP;
+END
+$$;
+
+-- Email addresses are unique per tenant
+DROP INDEX IF EXISTS employees_email_key;
+CREATE UNIQUE INDEX employees_email_key ON employees(tenant_id, email) WHERE deleted_at IS NULL;
+
+-- Record audit logs under the tenant of the audited row
+CREATE OR REPLACE FUNCTION audit_log_func() RETURNS TRIGGER AS $$
+DECLARE
+    changes_json JSONB;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        changes_json = to_jsonb(OLD);
+        INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+        VALUES (OLD.tenant_id, current_setting('app.user_id', TRUE)::UUID, 'DELETE', TG_TABLE_NAME, OLD.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN OLD;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        changes_json = jsonb_object_agg(key, value)
+        FROM (
+            SELECT key, value
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+        ) AS changed_fields;
+
+        IF changes_json IS NOT NULL AND changes_json <> '{}'::JSONB THEN
+            INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+            VALUES (NEW.tenant_id, current_setting('app.user_id', TRUE)::UUID, 'UPDATE', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'INSERT') THEN
+        changes_json = to_jsonb(NEW);
+        INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+        VALUES (NEW.tenant_id, current_setting('app.user_id', TRUE)::UUID, 'INSERT', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN NEW;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,75 @@
+-- Migration: manager_rls (down)
+-- Created at: 2025-05-26T10:00:00Z
+
+BEGIN;
+
+-- Restore the audit function from 009_tenants
+CREATE OR REPLACE FUNCTION audit_log_func() RETURNS TRIGGER AS $$
+DECLARE
+    changes_json JSONB;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        changes_json = to_jsonb(OLD);
+        INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+        VALUES (OLD.tenant_id, current_setting('app.user_id', TRUE)::UUID, 'DELETE', TG_TABLE_NAME, OLD.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN OLD;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        changes_json = jsonb_object_agg(key, value)
+        FROM (
+            SELECT key, value
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+        ) AS changed_fields;
+
+        IF changes_json IS NOT NULL AND changes_json <> '{}'::JSONB THEN
+            INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+            VALUES (NEW.tenant_id, current_setting('app.user_id', TRUE)::UUID, 'UPDATE', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'INSERT') THEN
+        changes_json = to_jsonb(NEW);
+        INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+        VALUES (NEW.tenant_id, current_setting('app.user_id', TRUE)::UUID, 'INSERT', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN NEW;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP POLICY IF EXISTS audit_logs_other_roles ON audit_logs;
+DROP POLICY IF EXISTS audit_logs_app_update ON audit_logs;
+DROP POLICY IF EXISTS audit_logs_app_select ON audit_logs;
+DROP POLICY IF EXISTS audit_logs_app_insert ON audit_logs;
+CREATE POLICY audit_logs_access ON audit_logs FOR ALL USING (true);
+
+DROP POLICY IF EXISTS goal_checkins_other_roles ON goal_checkins;
+DROP POLICY IF EXISTS goal_checkins_app_access ON goal_checkins;
+CREATE POLICY goal_checkins_access ON goal_checkins FOR ALL USING (true);
+
+DROP POLICY IF EXISTS goals_other_roles ON goals;
+DROP POLICY IF EXISTS goals_app_access ON goals;
+CREATE POLICY goals_access ON goals FOR ALL USING (true);
+
+DROP POLICY IF EXISTS assessments_other_roles ON assessments;
+DROP POLICY IF EXISTS assessments_app_delete ON assessments;
+DROP POLICY IF EXISTS assessments_app_update ON assessments;
+DROP POLICY IF EXISTS assessments_app_insert ON assessments;
+DROP POLICY IF EXISTS assessments_app_select ON assessments;
+CREATE POLICY assessments_access ON assessments FOR ALL USING (true);
+
+DROP POLICY IF EXISTS employees_app_delete ON employees;
+DROP POLICY IF EXISTS employees_app_update ON employees;
+DROP POLICY IF EXISTS employees_app_insert ON employees;
+DROP POLICY IF EXISTS employees_app_select ON employees;
+CREATE POLICY employees_app_policy ON employees
+    FOR ALL
+    TO byebob_app_role
+    USING (true);
+
+DROP FUNCTION IF EXISTS app_visible_employee_ids();
+DROP FUNCTION IF EXISTS app_managed_employee_ids();
+DROP FUNCTION IF EXISTS app_user_is_hr();
+DROP FUNCTION IF EXISTS app_user_id();
+
+COMMIT;
@@ -0,0 +1,145 @@
+-- Migration: manager_rls (up)
+-- Created at: 2025-05-26T10:00:00Z
+
+BEGIN;
+
+-- The acting employee, set by the application with
+-- set_config('app.user_id', ..., true). NULL when unset.
+CREATE OR REPLACE FUNCTION app_user_id() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.user_id', TRUE), '')::UUID
+$$ LANGUAGE sql STABLE;
+
+-- Whether the acting user works in HR and may see everyone in the tenant
+CREATE OR REPLACE FUNCTION app_user_is_hr() RETURNS BOOLEAN AS $$
+    SELECT COALESCE(NULLIF(current_setting('app.user_is_hr', TRUE), '')::BOOLEAN, FALSE)
+$$ LANGUAGE sql STABLE;
+
+-- Employees below the acting user in the reporting tree. Runs as the
+-- function owner so that the employees policies do not recurse into it; the
+-- tenant is filtered explicitly instead.
+CREATE OR REPLACE FUNCTION app_managed_employee_ids() RETURNS SETOF UUID AS $$
+    WITH RECURSIVE subtree AS (
+        SELECT id, 1 AS depth
+        FROM employees
+        WHERE manager_id = app_user_id() AND tenant_id = current_tenant_id()
+        UNION
+        SELECT e.id, s.depth + 1
+        FROM employees e
+        JOIN subtree s ON e.manager_id = s.id
+        WHERE e.tenant_id = current_tenant_id() AND s.depth < 64
+    )
+    SELECT DISTINCT id FROM subtree
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+-- The acting user and everyone below them
+CREATE OR REPLACE FUNCTION app_visible_employee_ids() RETURNS SETOF UUID AS $$
+    SELECT app_user_id() WHERE app_user_id() IS NOT NULL
+    UNION
+    SELECT app_managed_employee_ids()
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+GRANT EXECUTE ON FUNCTION app_user_id(), app_user_is_hr(),
+    app_managed_employee_ids(), app_visible_employee_ids() TO byebob_app_role;
+
+-- Employees: the app role sees themselves, their reporting subtree, or
+-- everyone when acting for HR. Only HR creates and deletes employees;
+-- employees may update their own record, with sensitive fields still guarded
+-- by restrict_sensitive_fields_update.
+DROP POLICY IF EXISTS employees_app_policy ON employees;
+
+CREATE POLICY employees_app_select ON employees FOR SELECT TO byebob_app_role
+    USING (app_user_is_hr() OR id IN (SELECT app_visible_employee_ids()));
+CREATE POLICY employees_app_insert ON employees FOR INSERT TO byebob_app_role
+    WITH CHECK (app_user_is_hr());
+CREATE POLICY employees_app_update ON employees FOR UPDATE TO byebob_app_role
+    USING (app_user_is_hr() OR id = app_user_id())
+    WITH CHECK (app_user_is_hr() OR id = app_user_id());
+CREATE POLICY employees_app_delete ON employees FOR DELETE TO byebob_app_role
+    USING (app_user_is_hr());
+
+-- Assessments: visible to the subject, their managers and the reviewer.
+-- Managers open and manage assessments of their reports; reviewers update
+-- the ones assigned to them.
+DROP POLICY IF EXISTS assessments_access ON assessments;
+
+CREATE POLICY assessments_app_select ON assessments FOR SELECT TO byebob_app_role
+    USING (app_user_is_hr()
+        OR employee_id IN (SELECT app_visible_employee_ids())
+        OR reviewer_id = app_user_id());
+CREATE POLICY assessments_app_insert ON assessments FOR INSERT TO byebob_app_role
+    WITH CHECK (app_user_is_hr() OR employee_id IN (SELECT app_managed_employee_ids()));
+CREATE POLICY assessments_app_update ON assessments FOR UPDATE TO byebob_app_role
+    USING (app_user_is_hr()
+        OR employee_id IN (SELECT app_managed_employee_ids())
+        OR reviewer_id = app_user_id());
+CREATE POLICY assessments_app_delete ON assessments FOR DELETE TO byebob_app_role
+    USING (app_user_is_hr() OR employee_id IN (SELECT app_managed_employee_ids()));
+CREATE POLICY assessments_other_roles ON assessments FOR ALL TO byebob_admin_role, byebob_readonly_role
+    USING (true);
+
+-- Goals: owned by the employee, managed by their managers
+DROP POLICY IF EXISTS goals_access ON goals;
+
+CREATE POLICY goals_app_access ON goals FOR ALL TO byebob_app_role
+    USING (app_user_is_hr() OR employee_id IN (SELECT app_visible_employee_ids()))
+    WITH CHECK (app_user_is_hr() OR employee_id IN (SELECT app_visible_employee_ids()));
+CREATE POLICY goals_other_roles ON goals FOR ALL TO byebob_admin_role, byebob_readonly_role
+    USING (true);
+
+-- Check-ins follow the visibility of their goal
+DROP POLICY IF EXISTS goal_checkins_access ON goal_checkins;
+
+CREATE POLICY goal_checkins_app_access ON goal_checkins FOR ALL TO byebob_app_role
+    USING (EXISTS (SELECT 1 FROM goals g WHERE g.id = goal_id))
+    WITH CHECK (EXISTS (SELECT 1 FROM goals g WHERE g.id = goal_id));
+CREATE POLICY goal_checkins_other_roles ON goal_checkins FOR ALL TO byebob_admin_role, byebob_readonly_role
+    USING (true);
+
+-- Audit logs are written by triggers on behalf of everyone but read by HR only
+DROP POLICY IF EXISTS audit_logs_access ON audit_logs;
+
+CREATE POLICY audit_logs_app_insert ON audit_logs FOR INSERT TO byebob_app_role
+    WITH CHECK (true);
+CREATE POLICY audit_logs_app_select ON audit_logs FOR SELECT TO byebob_app_role
+    USING (app_user_is_hr());
+CREATE POLICY audit_logs_app_update ON audit_logs FOR UPDATE TO byebob_app_role
+    USING (app_user_is_hr());
+CREATE POLICY audit_logs_other_roles ON audit_logs FOR ALL TO byebob_admin_role, byebob_readonly_role
+    USING (true);
+
+-- Read the acting user through app_user_id(), which tolerates the empty value
+-- a transaction-local setting leaves behind on a pooled connection
+CREATE OR REPLACE FUNCTION audit_log_func() RETURNS TRIGGER AS $$
+DECLARE
+    changes_json JSONB;
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        changes_json = to_jsonb(OLD);
+        INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+        VALUES (OLD.tenant_id, app_user_id(), 'DELETE', TG_TABLE_NAME, OLD.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN OLD;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        changes_json = jsonb_object_agg(key, value)
+        FROM (
+            SELECT key, value
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+        ) AS changed_fields;
+
+        IF changes_json IS NOT NULL AND changes_json <> '{}'::JSONB THEN
+            INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+            VALUES (NEW.tenant_id, app_user_id(), 'UPDATE', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'INSERT') THEN
+        changes_json = to_jsonb(NEW);
+        INSERT INTO audit_logs (tenant_id, user_id, action, table_name, record_id, changes)
+        VALUES (NEW.tenant_id, app_user_id(), 'INSERT', TG_TABLE_NAME, NEW.id, audit_redact(TG_TABLE_NAME, changes_json));
+        RETURN NEW;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
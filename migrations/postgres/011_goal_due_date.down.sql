@@ -0,0 +1,8 @@
+-- Migration: goal_due_date (down)
+-- Created at: 2025-05-29T10:00:00Z
+
+BEGIN;
+
+ALTER TABLE goals DROP COLUMN IF EXISTS due_date;
+
+COMMIT;
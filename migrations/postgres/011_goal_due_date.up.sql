@@ -0,0 +1,9 @@
+-- Migration: goal_due_date (up)
+-- Created at: 2025-05-29T10:00:00Z
+
+BEGIN;
+
+-- Optional deadline of a goal; active goals past it count as overdue
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS due_date DATE;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: http_storage (down)
+-- Created at: 2025-06-02T10:00:00Z
+
+BEGIN;
+
+DROP TABLE IF EXISTS http_storage;
+
+COMMIT;
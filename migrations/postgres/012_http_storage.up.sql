@@ -0,0 +1,16 @@
+-- Migration: http_storage (up)
+-- Created at: 2025-06-02T10:00:00Z
+
+BEGIN;
+
+-- Rate limit counters and CSRF tokens shared by every server instance. Not
+-- tenant-owned: keys are client IPs, employee IDs and random tokens.
+CREATE TABLE IF NOT EXISTS http_storage (
+    key VARCHAR(255) PRIMARY KEY,
+    value BYTEA NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_http_storage_expires_at ON http_storage (expires_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Migration: employee_search (down)
+-- Created at: 2025-06-09T10:00:00Z
+
+BEGIN;
+
+DROP EXTENSION IF EXISTS pg_trgm;
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Migration: employee_search (up)
+-- Created at: 2025-06-09T10:00:00Z
+
+BEGIN;
+
+-- Trigram similarity for typo-tolerant directory search. Search documents are
+-- built per query from employees and their position, department and site, so
+-- employees is not rewritten to add a stored column.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+COMMIT;
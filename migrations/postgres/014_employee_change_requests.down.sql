@@ -0,0 +1,10 @@
+-- Migration: employee_change_requests (down)
+-- Created at: 2025-06-12T10:00:00Z
+
+BEGIN;
+
+DROP FUNCTION IF EXISTS employee_manager_chain(UUID);
+DROP PROCEDURE IF EXISTS approve_employee_change_request(UUID, INTEGER, TEXT);
+DROP TABLE IF EXISTS employee_change_requests;
+
+COMMIT;
@@ -0,0 +1,142 @@
+-- Migration: employee_change_requests (up)
+-- Created at: 2025-06-12T10:00:00Z
+
+BEGIN;
+
+-- Changes to the sensitive employee fields guarded by
+-- restrict_sensitive_fields_update, requested by the employee or HR and
+-- applied once HR approves them. Changes maps field names to their new
+-- values as text, with an empty string clearing a date.
+CREATE TABLE IF NOT EXISTS employee_change_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL DEFAULT current_tenant_id(),
+    employee_id UUID NOT NULL,
+    requested_by UUID,
+    changes JSONB NOT NULL,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by UUID,
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT fk_employee_change_requests_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    CONSTRAINT fk_change_request_employee FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    CONSTRAINT fk_change_request_requester FOREIGN KEY (requested_by) REFERENCES employees(id) ON DELETE SET NULL,
+    CONSTRAINT fk_change_request_reviewer FOREIGN KEY (reviewed_by) REFERENCES employees(id) ON DELETE SET NULL,
+    CONSTRAINT change_request_status_check CHECK (status IN ('pending', 'approved', 'rejected')),
+    CONSTRAINT change_request_changes_check CHECK (
+        jsonb_typeof(changes) = 'object'
+        AND changes <> '{}'::JSONB
+        AND changes - ARRAY['employment_type', 'start_date', 'end_date', 'status'] = '{}'::JSONB
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_change_requests_tenant_id ON employee_change_requests(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_employee_change_requests_employee_id ON employee_change_requests(employee_id);
+CREATE INDEX IF NOT EXISTS idx_employee_change_requests_status ON employee_change_requests(status);
+
+-- An employee has at most one request awaiting review
+CREATE UNIQUE INDEX IF NOT EXISTS employee_change_requests_pending_key
+    ON employee_change_requests(employee_id) WHERE status = 'pending';
+
+CREATE TRIGGER employee_change_requests_audit
+AFTER INSERT OR UPDATE OR DELETE ON employee_change_requests
+FOR EACH ROW EXECUTE FUNCTION audit_log_func();
+
+-- Requests are visible to the employee, their managers and HR. Employees
+-- request changes to their own record and HR to anyone's; only HR reviews.
+ALTER TABLE employee_change_requests ENABLE ROW LEVEL SECURITY;
+ALTER TABLE employee_change_requests FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY employee_change_requests_tenant_isolation ON employee_change_requests AS RESTRICTIVE FOR ALL
+    USING (tenant_id = current_tenant_id())
+    WITH CHECK (tenant_id = current_tenant_id());
+CREATE POLICY employee_change_requests_app_select ON employee_change_requests FOR SELECT TO byebob_app_role
+    USING (app_user_is_hr() OR employee_id IN (SELECT app_visible_employee_ids()));
+CREATE POLICY employee_change_requests_app_insert ON employee_change_requests FOR INSERT TO byebob_app_role
+    WITH CHECK (requested_by = app_user_id() AND (app_user_is_hr() OR employee_id = app_user_id()));
+CREATE POLICY employee_change_requests_app_update ON employee_change_requests FOR UPDATE TO byebob_app_role
+    USING (app_user_is_hr());
+CREATE POLICY employee_change_requests_other_roles ON employee_change_requests FOR ALL TO byebob_admin_role, byebob_readonly_role
+    USING (true);
+
+-- Apply a pending request to its employee and mark it approved. Runs as the
+-- owner, so that the change passes restrict_sensitive_fields_update, and
+-- therefore checks that the acting user is HR and filters the tenant
+-- explicitly. The employee's version must still be the one the request was
+-- reviewed against. A procedure rather than a function, so that calling it
+-- is never mistaken for a read and routed to a replica.
+CREATE OR REPLACE PROCEDURE approve_employee_change_request(request_id UUID, expected_version INTEGER, note TEXT)
+AS $$
+DECLARE
+    request employee_change_requests%ROWTYPE;
+BEGIN
+    IF NOT app_user_is_hr() THEN
+        RAISE EXCEPTION 'Only HR can approve employee change requests'
+            USING ERRCODE = 'insufficient_privilege';
+    END IF;
+
+    SELECT * INTO request
+    FROM employee_change_requests
+    WHERE id = request_id AND tenant_id = current_tenant_id() AND status = 'pending'
+    FOR UPDATE;
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'Pending change request % not found', request_id
+            USING ERRCODE = 'no_data_found';
+    END IF;
+
+    UPDATE employees
+    SET employment_type = COALESCE(request.changes->>'employment_type', employment_type),
+        start_date = COALESCE((request.changes->>'start_date')::DATE, start_date),
+        end_date = CASE WHEN request.changes ? 'end_date'
+            THEN NULLIF(request.changes->>'end_date', '')::DATE ELSE end_date END,
+        status = COALESCE(request.changes->>'status', status),
+        updated_at = NOW(),
+        version = version + 1
+    WHERE id = request.employee_id AND tenant_id = current_tenant_id()
+        AND deleted_at IS NULL AND version = expected_version;
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'Employee % is not at version %', request.employee_id, expected_version
+            USING ERRCODE = 'serialization_failure';
+    END IF;
+
+    UPDATE employee_change_requests
+    SET status = 'approved', reviewed_by = app_user_id(), review_note = note,
+        reviewed_at = NOW(), updated_at = NOW(), version = version + 1
+    WHERE id = request_id;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+REVOKE EXECUTE ON PROCEDURE approve_employee_change_request(UUID, INTEGER, TEXT) FROM PUBLIC;
+GRANT EXECUTE ON PROCEDURE approve_employee_change_request(UUID, INTEGER, TEXT) TO byebob_app_role;
+
+-- The managers above an employee, nearest first. Employees cannot see their
+-- managers' records, so this runs as the function owner and only returns
+-- the names and positions, for employees the acting user can see.
+CREATE OR REPLACE FUNCTION employee_manager_chain(target UUID)
+RETURNS TABLE (id UUID, display_name VARCHAR, email VARCHAR, position_title VARCHAR, depth INTEGER) AS $$
+    WITH RECURSIVE chain AS (
+        SELECT m.id, m.manager_id, 1 AS depth
+        FROM employees e
+        JOIN employees m ON m.id = e.manager_id AND m.tenant_id = e.tenant_id
+        WHERE e.id = target AND e.tenant_id = current_tenant_id()
+            AND (app_user_is_hr() OR target IN (SELECT app_visible_employee_ids()))
+        UNION
+        SELECT m.id, m.manager_id, c.depth + 1
+        FROM chain c
+        JOIN employees m ON m.id = c.manager_id
+        WHERE m.tenant_id = current_tenant_id() AND c.depth < 64
+    )
+    SELECT m.id, m.display_name, m.email, p.title, c.depth
+    FROM chain c
+    JOIN employees m ON m.id = c.id
+    LEFT JOIN positions p ON p.id = m.position_id
+    WHERE m.deleted_at IS NULL
+    ORDER BY c.depth
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public;
+
+GRANT EXECUTE ON FUNCTION employee_manager_chain(UUID) TO byebob_app_role;
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- Migration: organisation_rls (down)
+-- Created at: 2025-06-16T10:00:00Z
+
+BEGIN;
+
+-- Restore the permissive policies from 009_tenants
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['positions', 'departments', 'sites'] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS %s_app_select ON %I', tbl, tbl);
+        EXECUTE format('DROP POLICY IF EXISTS %s_app_insert ON %I', tbl, tbl);
+        EXECUTE format('DROP POLICY IF EXISTS %s_app_update ON %I', tbl, tbl);
+        EXECUTE format('DROP POLICY IF EXISTS %s_app_delete ON %I', tbl, tbl);
+        EXECUTE format('DROP POLICY IF EXISTS %s_other_roles ON %I', tbl, tbl);
+        EXECUTE format('CREATE POLICY %s_access ON %I FOR ALL USING (true)', tbl, tbl);
+    END LOOP;
+END
+$$;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- Migration: organisation_rls (up)
+-- Created at: 2025-06-16T10:00:00Z
+
+BEGIN;
+
+-- Positions, departments and sites: everyone in the tenant reads them to
+-- render the organisation, but only HR changes them. Replaces the permissive
+-- <table>_access policies from 009_tenants.
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['positions', 'departments', 'sites'] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS %s_access ON %I', tbl, tbl);
+
+        EXECUTE format(
+            'CREATE POLICY %s_app_select ON %I FOR SELECT TO byebob_app_role
+                USING (true)', tbl, tbl);
+        EXECUTE format(
+            'CREATE POLICY %s_app_insert ON %I FOR INSERT TO byebob_app_role
+                WITH CHECK (app_user_is_hr())', tbl, tbl);
+        EXECUTE format(
+            'CREATE POLICY %s_app_update ON %I FOR UPDATE TO byebob_app_role
+                USING (app_user_is_hr())
+                WITH CHECK (app_user_is_hr())', tbl, tbl);
+        EXECUTE format(
+            'CREATE POLICY %s_app_delete ON %I FOR DELETE TO byebob_app_role
+                USING (app_user_is_hr())', tbl, tbl);
+        EXECUTE format(
+            'CREATE POLICY %s_other_roles ON %I FOR ALL TO byebob_admin_role, byebob_readonly_role
+                USING (true)', tbl, tbl);
+    END LOOP;
+END
+$$;
+
+COMMIT;
@@ -0,0 +1,39 @@
+-- Migration: employee_self_update (down)
+-- Created at: 2025-06-18T10:00:00Z
+
+BEGIN;
+
+-- Restore the trigger function from 005_sensitive_field_errors
+CREATE OR REPLACE FUNCTION restrict_sensitive_fields_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed_field TEXT;
+BEGIN
+    -- Check if the current user has the app role but not admin role
+    IF (SELECT pg_has_role(CURRENT_USER, 'byebob_app_role', 'MEMBER') AND 
+        NOT pg_has_role(CURRENT_USER, 'byebob_admin_role', 'MEMBER')) THEN
+        
+        -- Find the first sensitive field that was modified
+        IF OLD.employment_type IS DISTINCT FROM NEW.employment_type THEN
+            changed_field := 'employment_type';
+        ELSIF OLD.start_date IS DISTINCT FROM NEW.start_date THEN
+            changed_field := 'start_date';
+        ELSIF OLD.end_date IS DISTINCT FROM NEW.end_date THEN
+            changed_field := 'end_date';
+        ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+            changed_field := 'status';
+        END IF;
+
+        IF changed_field IS NOT NULL THEN
+            RAISE EXCEPTION 'Not authorized to modify sensitive employee fields'
+                USING ERRCODE = 'insufficient_privilege',
+                      COLUMN = changed_field,
+                      TABLE = TG_TABLE_NAME;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,55 @@
+-- Migration: employee_self_update (up)
+-- Created at: 2025-06-18T10:00:00Z
+
+BEGIN;
+
+-- Employees updating their own record may only change the profile fields
+-- they maintain themselves; everything else goes through HR or an approved
+-- change request. employees_app_update lets a non-HR actor update only their
+-- own row, so the trigger restricts which columns that update may touch. It
+-- raises the sensitive field error so the application reports the column.
+CREATE OR REPLACE FUNCTION restrict_sensitive_fields_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed_field TEXT;
+BEGIN
+    -- Check if the current user has the app role but not admin role
+    IF (SELECT pg_has_role(CURRENT_USER, 'byebob_app_role', 'MEMBER') AND 
+        NOT pg_has_role(CURRENT_USER, 'byebob_admin_role', 'MEMBER')) THEN
+        
+        -- Find the first sensitive field that was modified
+        IF OLD.employment_type IS DISTINCT FROM NEW.employment_type THEN
+            changed_field := 'employment_type';
+        ELSIF OLD.start_date IS DISTINCT FROM NEW.start_date THEN
+            changed_field := 'start_date';
+        ELSIF OLD.end_date IS DISTINCT FROM NEW.end_date THEN
+            changed_field := 'end_date';
+        ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+            changed_field := 'status';
+        END IF;
+
+        -- Outside HR, find the first modified field that is not self-service
+        IF changed_field IS NULL AND NOT app_user_is_hr() THEN
+            SELECT key INTO changed_field
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+                AND key NOT IN ('display_name', 'address', 'address_bidx',
+                    'profile_picture_url', 'updated_at', 'version')
+            ORDER BY key
+            LIMIT 1;
+        END IF;
+
+        IF changed_field IS NOT NULL THEN
+            RAISE EXCEPTION 'Not authorized to modify sensitive employee fields'
+                USING ERRCODE = 'insufficient_privilege',
+                      COLUMN = changed_field,
+                      TABLE = TG_TABLE_NAME;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
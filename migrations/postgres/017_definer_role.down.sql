@@ -0,0 +1,23 @@
+-- Migration: definer_role (down)
+-- Created at: 2025-06-20T10:00:00Z
+
+BEGIN;
+
+-- Hand the functions back to the migration user
+ALTER FUNCTION app_managed_employee_ids() OWNER TO CURRENT_USER;
+ALTER FUNCTION app_visible_employee_ids() OWNER TO CURRENT_USER;
+ALTER FUNCTION employee_manager_chain(UUID) OWNER TO CURRENT_USER;
+ALTER PROCEDURE approve_employee_change_request(UUID, INTEGER, TEXT) OWNER TO CURRENT_USER;
+
+DROP POLICY IF EXISTS employees_definer_access ON employees;
+DROP POLICY IF EXISTS employee_change_requests_definer_access ON employee_change_requests;
+DROP POLICY IF EXISTS positions_definer_select ON positions;
+DROP POLICY IF EXISTS audit_logs_definer_insert ON audit_logs;
+
+REVOKE ALL ON employees, employee_change_requests, positions, audit_redacted_columns, audit_logs
+    FROM byebob_definer_role;
+REVOKE ALL ON SCHEMA public FROM byebob_definer_role;
+
+DROP ROLE IF EXISTS byebob_definer_role;
+
+COMMIT;
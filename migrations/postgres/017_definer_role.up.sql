@@ -0,0 +1,52 @@
+-- Migration: definer_role (up)
+-- Created at: 2025-06-20T10:00:00Z
+
+BEGIN;
+
+-- The SECURITY DEFINER functions from 010_manager_rls and
+-- 014_employee_change_requests read tables with FORCE ROW LEVEL SECURITY,
+-- which applies to their owner too. Owned by the migration user, they only
+-- worked when that user was a superuser or had BYPASSRLS. They now run as a
+-- dedicated NOLOGIN role with just the privileges and policies they need, so
+-- neither the migrations nor the functions need to bypass row-level security.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'byebob_definer_role') THEN
+        CREATE ROLE byebob_definer_role NOLOGIN;
+    END IF;
+END
+$$;
+
+-- Handing a function to another role needs membership of that role, and the
+-- new owner needs CREATE on the schema while ownership moves
+GRANT byebob_definer_role TO CURRENT_USER;
+GRANT USAGE, CREATE ON SCHEMA public TO byebob_definer_role;
+
+ALTER FUNCTION app_managed_employee_ids() OWNER TO byebob_definer_role;
+ALTER FUNCTION app_visible_employee_ids() OWNER TO byebob_definer_role;
+ALTER FUNCTION employee_manager_chain(UUID) OWNER TO byebob_definer_role;
+ALTER PROCEDURE approve_employee_change_request(UUID, INTEGER, TEXT) OWNER TO byebob_definer_role;
+
+REVOKE CREATE ON SCHEMA public FROM byebob_definer_role;
+
+-- What the functions read and write, including the audit rows the employee
+-- and change request triggers insert on their behalf
+GRANT SELECT, UPDATE ON employees TO byebob_definer_role;
+GRANT SELECT, UPDATE ON employee_change_requests TO byebob_definer_role;
+GRANT SELECT ON positions TO byebob_definer_role;
+GRANT SELECT ON audit_redacted_columns TO byebob_definer_role;
+GRANT INSERT ON audit_logs TO byebob_definer_role;
+
+-- Each function checks the acting user itself, so the definer role sees
+-- every row of the tables it uses. The restrictive tenant isolation
+-- policies still apply, keeping it inside the current tenant.
+CREATE POLICY employees_definer_access ON employees FOR ALL TO byebob_definer_role
+    USING (true) WITH CHECK (true);
+CREATE POLICY employee_change_requests_definer_access ON employee_change_requests FOR ALL TO byebob_definer_role
+    USING (true) WITH CHECK (true);
+CREATE POLICY positions_definer_select ON positions FOR SELECT TO byebob_definer_role
+    USING (true);
+CREATE POLICY audit_logs_definer_insert ON audit_logs FOR INSERT TO byebob_definer_role
+    WITH CHECK (true);
+
+COMMIT;
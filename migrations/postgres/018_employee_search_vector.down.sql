@@ -0,0 +1,60 @@
+-- Migration: employee_search_vector (down)
+-- Created at: 2025-06-22T10:00:00Z
+
+BEGIN;
+
+DROP INDEX IF EXISTS idx_sites_name_trgm;
+DROP INDEX IF EXISTS idx_departments_name_trgm;
+DROP INDEX IF EXISTS idx_positions_title_trgm;
+
+-- Restore the trigger function from 016_employee_self_update
+CREATE OR REPLACE FUNCTION restrict_sensitive_fields_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed_field TEXT;
+BEGIN
+    -- Check if the current user has the app role but not admin role
+    IF (SELECT pg_has_role(CURRENT_USER, 'byebob_app_role', 'MEMBER') AND 
+        NOT pg_has_role(CURRENT_USER, 'byebob_admin_role', 'MEMBER')) THEN
+        
+        -- Find the first sensitive field that was modified
+        IF OLD.employment_type IS DISTINCT FROM NEW.employment_type THEN
+            changed_field := 'employment_type';
+        ELSIF OLD.start_date IS DISTINCT FROM NEW.start_date THEN
+            changed_field := 'start_date';
+        ELSIF OLD.end_date IS DISTINCT FROM NEW.end_date THEN
+            changed_field := 'end_date';
+        ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+            changed_field := 'status';
+        END IF;
+
+        -- Outside HR, find the first modified field that is not self-service
+        IF changed_field IS NULL AND NOT app_user_is_hr() THEN
+            SELECT key INTO changed_field
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+                AND key NOT IN ('display_name', 'address', 'address_bidx',
+                    'profile_picture_url', 'updated_at', 'version')
+            ORDER BY key
+            LIMIT 1;
+        END IF;
+
+        IF changed_field IS NOT NULL THEN
+            RAISE EXCEPTION 'Not authorized to modify sensitive employee fields'
+                USING ERRCODE = 'insufficient_privilege',
+                      COLUMN = changed_field,
+                      TABLE = TG_TABLE_NAME;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DELETE FROM audit_redacted_columns WHERE table_name = 'employees' AND column_name = 'search_vector';
+
+-- lint:ignore drop-column
+ALTER TABLE employees DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
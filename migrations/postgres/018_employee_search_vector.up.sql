@@ -0,0 +1,77 @@
+-- Migration: employee_search_vector (up)
+-- Created at: 2025-06-22T10:00:00Z
+
+BEGIN;
+
+-- The employee's own part of the directory search document: names weigh
+-- most, then the email and its local part split into words. Position,
+-- department and site are added per query from their own tables. Adding a
+-- stored generated column rewrites employees once, under an exclusive lock,
+-- which takes seconds for a directory of tens of thousands of employees.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(first_name, '') || ' ' || COALESCE(middle_name, '') || ' '
+        || COALESCE(last_name, '') || ' ' || COALESCE(display_name, '')), 'A')
+    || setweight(to_tsvector('simple', COALESCE(email, '') || ' '
+        || translate(split_part(COALESCE(email, ''), '@', 1), '._-+', '    ')), 'B')
+    ) STORED;
+
+-- The vector repeats the names and email, which anonymising an employee
+-- redacts from their audit entries, so the entries never keep it
+INSERT INTO audit_redacted_columns (table_name, column_name) VALUES
+    ('employees', 'search_vector')
+ON CONFLICT DO NOTHING;
+
+-- The vector follows the self-service name fields, so it may change with them
+CREATE OR REPLACE FUNCTION restrict_sensitive_fields_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed_field TEXT;
+BEGIN
+    -- Check if the current user has the app role but not admin role
+    IF (SELECT pg_has_role(CURRENT_USER, 'byebob_app_role', 'MEMBER') AND 
+        NOT pg_has_role(CURRENT_USER, 'byebob_admin_role', 'MEMBER')) THEN
+        
+        -- Find the first sensitive field that was modified
+        IF OLD.employment_type IS DISTINCT FROM NEW.employment_type THEN
+            changed_field := 'employment_type';
+        ELSIF OLD.start_date IS DISTINCT FROM NEW.start_date THEN
+            changed_field := 'start_date';
+        ELSIF OLD.end_date IS DISTINCT FROM NEW.end_date THEN
+            changed_field := 'end_date';
+        ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+            changed_field := 'status';
+        END IF;
+
+        -- Outside HR, find the first modified field that is not self-service
+        IF changed_field IS NULL AND NOT app_user_is_hr() THEN
+            SELECT key INTO changed_field
+            FROM jsonb_each(to_jsonb(NEW)) AS new_fields(key, value)
+            JOIN jsonb_each(to_jsonb(OLD)) AS old_fields(key, value) USING (key)
+            WHERE new_fields.value IS DISTINCT FROM old_fields.value
+                AND key NOT IN ('display_name', 'address', 'address_bidx',
+                    'profile_picture_url', 'search_vector', 'updated_at', 'version')
+            ORDER BY key
+            LIMIT 1;
+        END IF;
+
+        IF changed_field IS NOT NULL THEN
+            RAISE EXCEPTION 'Not authorized to modify sensitive employee fields'
+                USING ERRCODE = 'insufficient_privilege',
+                      COLUMN = changed_field,
+                      TABLE = TG_TABLE_NAME;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigram indexes for fuzzy matching of position, department and site names.
+-- These tables stay small, so the indexes are built in the transaction; the
+-- employee indexes follow in migrations of their own, built concurrently.
+CREATE INDEX IF NOT EXISTS idx_positions_title_trgm ON positions USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_departments_name_trgm ON departments USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_sites_name_trgm ON sites USING GIN (name gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Migration: employee_search_vector_index (down)
+-- Created at: 2025-06-22T10:10:00Z
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_employees_search_vector;
@@ -0,0 +1,8 @@
+-- Migration: employee_search_vector_index (up)
+-- Created at: 2025-06-22T10:10:00Z
+
+-- Full-text matching of the employee's part of the search document.
+-- CREATE INDEX CONCURRENTLY cannot run in a transaction, and a migration file
+-- with several statements runs in one, so this migration holds nothing else.
+-- A failed build leaves an invalid index behind; drop it before retrying.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_employees_search_vector ON employees USING GIN (search_vector);
@@ -0,0 +1,4 @@
+-- Migration: employee_display_name_trgm (down)
+-- Created at: 2025-06-22T10:20:00Z
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_employees_display_name_trgm;
@@ -0,0 +1,6 @@
+-- Migration: employee_display_name_trgm (up)
+-- Created at: 2025-06-22T10:20:00Z
+
+-- Fuzzy matching of display names. See 019_employee_search_vector_index for
+-- why this migration holds a single statement.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_employees_display_name_trgm ON employees USING GIN (display_name gin_trgm_ops);
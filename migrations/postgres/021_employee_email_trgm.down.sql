@@ -0,0 +1,4 @@
+-- Migration: employee_email_trgm (down)
+-- Created at: 2025-06-22T10:30:00Z
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_employees_email_local_trgm;
@@ -0,0 +1,7 @@
+-- Migration: employee_email_trgm (up)
+-- Created at: 2025-06-22T10:30:00Z
+
+-- Fuzzy matching of the local part of email addresses. See
+-- 019_employee_search_vector_index for why this migration holds a single
+-- statement.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_employees_email_local_trgm ON employees USING GIN ((split_part(email, '@', 1)) gin_trgm_ops);
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/gfurduy/byebob/scripts/db"
+)
+
+func main() {
+	db.TestManagerIsolation()
+}
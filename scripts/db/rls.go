@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gfurduy/byebob/config"
+	"github.com/jackc/pgx/v5"
+)
+
+// rlsTenantID is the default tenant created by migration 009_tenants
+const rlsTenantID = "00000000-0000-0000-0000-000000000001"
+
+// TestManagerIsolation checks that the row-level security policies stop a
+// manager from reading another team's employees and assessments, even with
+// raw queries. Fixtures are created in a transaction that is rolled back, so
+// the check leaves no data behind. It connects as the application user when
+// DB_APP_PASSWORD is set, so neither superuser nor BYPASSRLS is needed, and
+// otherwise as the migration user, which must then be a member of
+// byebob_app_role.
+func TestManagerIsolation() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.Println("Starting row-level security test...")
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, cfg.AppConnectionString())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		log.Fatalf("Failed to start transaction: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Act as the application role so the policies apply even when the
+	// connection itself bypasses them, and the role policies match
+	if _, err := tx.Exec(ctx, "SET LOCAL ROLE byebob_app_role"); err != nil {
+		log.Fatalf("Failed to switch to byebob_app_role: %v", err)
+	}
+
+	// HR creates the fixtures: two managers with one report each, and an
+	// assessment for each report reviewed by their manager
+	setActor(ctx, tx, "", true)
+	managerA := insertEmployee(ctx, tx, "Manager A", "")
+	reportA := insertEmployee(ctx, tx, "Report A", managerA)
+	managerB := insertEmployee(ctx, tx, "Manager B", "")
+	reportB := insertEmployee(ctx, tx, "Report B", managerB)
+
+	var templateID string
+	if err := tx.QueryRow(ctx,
+		"INSERT INTO assessment_templates (name) VALUES ('RLS check') RETURNING id",
+	).Scan(&templateID); err != nil {
+		log.Fatalf("Failed to create assessment template: %v", err)
+	}
+	assessmentA := insertAssessment(ctx, tx, templateID, reportA, managerA)
+	assessmentB := insertAssessment(ctx, tx, templateID, reportB, managerB)
+
+	failures := 0
+	check := func(name string, ok bool) {
+		if ok {
+			fmt.Printf("✅ %s\n", name)
+			return
+		}
+		fmt.Printf("❌ %s\n", name)
+		failures++
+	}
+
+	// Manager A sees their own team only
+	setActor(ctx, tx, managerA, false)
+	check("manager sees their report", visible(ctx, tx, "employees", reportA))
+	check("manager cannot see another manager", !visible(ctx, tx, "employees", managerB))
+	check("manager cannot see another team's report", !visible(ctx, tx, "employees", reportB))
+	check("manager sees their team's assessment", visible(ctx, tx, "assessments", assessmentA))
+	check("manager cannot see another team's assessment", !visible(ctx, tx, "assessments", assessmentB))
+
+	var count int
+	if err := tx.QueryRow(ctx,
+		"SELECT COUNT(*) FROM assessments WHERE employee_id = $1", reportB,
+	).Scan(&count); err != nil {
+		log.Fatalf("Raw query failed: %v", err)
+	}
+	check("raw query returns no rows of another team", count == 0)
+
+	tag, err := tx.Exec(ctx, "UPDATE assessments SET status = 'in_progress' WHERE id = $1", assessmentB)
+	if err != nil {
+		log.Fatalf("Raw update failed: %v", err)
+	}
+	check("manager cannot update another team's assessment", tag.RowsAffected() == 0)
+
+	// A report sees themselves but not their manager's other data
+	setActor(ctx, tx, reportA, false)
+	check("employee sees their own assessment", visible(ctx, tx, "assessments", assessmentA))
+	check("employee cannot see their manager", !visible(ctx, tx, "employees", managerA))
+
+	// The SECURITY DEFINER functions look past the acting user's policies
+	// as byebob_definer_role, which must not need to bypass them
+	rows, err := tx.Query(ctx, "SELECT id FROM employee_manager_chain($1)", reportA)
+	if err != nil {
+		log.Fatalf("Failed to read manager chain: %v", err)
+	}
+	chain, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		log.Fatalf("Failed to read manager chain: %v", err)
+	}
+	check("employee sees their manager in the chain", len(chain) == 1 && chain[0] == managerA)
+
+	var owners int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM pg_proc
+		WHERE proname IN ('app_managed_employee_ids', 'app_visible_employee_ids',
+				'employee_manager_chain', 'approve_employee_change_request')
+			AND proowner = 'byebob_definer_role'::regrole
+	`).Scan(&owners); err != nil {
+		log.Fatalf("Failed to read function owners: %v", err)
+	}
+	check("definer functions are owned by byebob_definer_role", owners == 4)
+
+	// Without an acting user nothing is visible
+	setActor(ctx, tx, "", false)
+	check("anonymous session sees no employees", !visible(ctx, tx, "employees", reportA))
+
+	// HR sees everyone
+	setActor(ctx, tx, "", true)
+	check("HR sees every assessment", visible(ctx, tx, "assessments", assessmentA) && visible(ctx, tx, "assessments", assessmentB))
+
+	if failures > 0 {
+		log.Fatalf("%d row-level security checks failed", failures)
+	}
+	fmt.Println("\nAll row-level security checks passed")
+}
+
+// setActor scopes the rest of the transaction to the tenant and acting user
+func setActor(ctx context.Context, tx pgx.Tx, employeeID string, hr bool) {
+	_, err := tx.Exec(ctx, `
+		SELECT set_config('app.tenant_id', $1, true),
+			set_config('app.user_id', $2, true),
+			set_config('app.user_is_hr', $3, true)
+	`, rlsTenantID, employeeID, fmt.Sprint(hr))
+	if err != nil {
+		log.Fatalf("Failed to set session: %v", err)
+	}
+}
+
+// insertEmployee creates a fixture employee and returns its ID
+func insertEmployee(ctx context.Context, tx pgx.Tx, name, managerID string) string {
+	var id string
+	err := tx.QueryRow(ctx, `
+		INSERT INTO employees (first_name, last_name, display_name, email, employment_type, start_date, manager_id)
+		VALUES ($1, 'RLS', $1, 'rls-' || md5(random()::text) || '@example.com', 'full_time', CURRENT_DATE, NULLIF($2, '')::uuid)
+		RETURNING id
+	`, name, managerID).Scan(&id)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", name, err)
+	}
+	return id
+}
+
+// insertAssessment creates a fixture assessment and returns its ID
+func insertAssessment(ctx context.Context, tx pgx.Tx, templateID, employeeID, reviewerID string) string {
+	var id string
+	err := tx.QueryRow(ctx, `
+		INSERT INTO assessments (template_id, employee_id, reviewer_id)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, templateID, employeeID, reviewerID).Scan(&id)
+	if err != nil {
+		log.Fatalf("Failed to create assessment: %v", err)
+	}
+	return id
+}
+
+// visible reports whether the current session can read the row
+func visible(ctx context.Context, tx pgx.Tx, table, id string) bool {
+	var exists bool
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", table), id,
+	).Scan(&exists)
+	if err != nil {
+		log.Fatalf("Failed to query %s: %v", table, err)
+	}
+	return exists
+}
@@ -0,0 +1,93 @@
+// Package static embeds the static web assets served under /static
+package static
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"strings"
+)
+
+// FS holds the static assets, laid out as they are served, and the manifest
+// mapping each asset to its fingerprinted name
+//
+//go:embed css js manifest.json
+var FS embed.FS
+
+// ManifestFile names the manifest within FS, written by cmd/assets
+const ManifestFile = "manifest.json"
+
+// Required are the assets every page loads: the compiled Tailwind stylesheet
+// and the htmx build and extensions vendored at the versions pinned in the
+// Makefile
+var Required = []string{
+	"css/main.css",
+	"js/htmx.min.js",
+	"js/ext/response-targets.js",
+}
+
+// manifest maps asset paths, such as "css/main.css", to their fingerprinted
+// paths, such as "css/main.1a2b3c4d.css"
+var manifest = mustLoadManifest()
+
+// originals maps fingerprinted paths back to the asset paths
+var originals = invert(manifest)
+
+// URL returns the fingerprinted URL of an asset, which may be cached for
+// good. Assets missing from the manifest keep their plain URL.
+func URL(path string) string {
+	if fingerprinted, ok := manifest[path]; ok {
+		return "/static/" + fingerprinted
+	}
+	return "/static/" + path
+}
+
+// Lookup resolves a path requested under /static to the asset to serve and
+// whether the path was fingerprinted. Only assets in the manifest are served.
+func Lookup(requested string) (path string, fingerprinted bool, ok bool) {
+	requested = strings.TrimPrefix(requested, "/")
+	if path, ok := originals[requested]; ok {
+		return path, true, true
+	}
+	_, ok = manifest[requested]
+	return requested, false, ok
+}
+
+// Fingerprint returns the fingerprinted path of an asset, for use as its ETag
+func Fingerprint(path string) string {
+	return manifest[path]
+}
+
+// mustLoadManifest reads the embedded manifest, which cmd/assets generates
+func mustLoadManifest() map[string]string {
+	data, err := FS.ReadFile(ManifestFile)
+	if err != nil {
+		panic("static: " + err.Error())
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic("static: invalid " + ManifestFile + ": " + err.Error())
+	}
+	return m
+}
+
+// Missing returns the required assets that are missing from fsys or empty
+func Missing(fsys fs.FS) []string {
+	var missing []string
+	for _, name := range Required {
+		info, err := fs.Stat(fsys, name)
+		if err != nil || info.Size() == 0 {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// invert swaps the keys and values of a map
+func invert(m map[string]string) map[string]string {
+	inverted := make(map[string]string, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted
+}
@@ -0,0 +1,9 @@
+/** @type {import('tailwindcss').Config} */
+module.exports = {
+  // Classes are collected from the templ sources and the code generated from them
+  content: ["./internal/templates/**/*.templ", "./internal/templates/**/*.go"],
+  theme: {
+    extend: {},
+  },
+  plugins: [],
+};